@@ -0,0 +1,261 @@
+// Package bot plays boardbots.dev games with a Policy. The Runner takes
+// care of everything around picking a move: logging in, finding the bot's
+// seat, waiting for its turn, submitting moves, reconnecting and noticing
+// when the game is over.
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rwsargent/boardbots-go/client"
+)
+
+const (
+	minReconnectDelay = 100 * time.Millisecond
+	maxReconnectDelay = 10 * time.Second
+)
+
+type (
+	// Policy picks the bot's next move. It's only asked when it is the bot's
+	// turn. The runner fills in the move's player.
+	Policy[S any] interface {
+		ChooseMove(ctx context.Context, state S) (client.MoveT, error)
+	}
+
+	// PolicyFunc adapts a function to a Policy.
+	PolicyFunc[S any] func(ctx context.Context, state S) (client.MoveT, error)
+
+	// Searcher is a Policy that searches for its moves, and can tell how
+	// each search went.
+	Searcher[S any] interface {
+		Policy[S]
+		ChooseMoveStats(ctx context.Context, state S) (client.MoveT, SearchStats, error)
+	}
+
+	// SearchStats describe the search behind a move.
+	SearchStats struct {
+		// Depth is the deepest completed search.
+		Depth int
+		// Nodes counts the positions visited, over every depth.
+		Nodes int
+		// TableProbes counts the lookups in the transposition table, and
+		// TableHits the ones that found a position searched before.
+		TableProbes, TableHits int
+	}
+
+	// Rules tell the runner about the state of a type of game.
+	Rules[S any] interface {
+		// PlayerTurn returns the seat of the player to move, counting from 1
+		// in the order of the game's players.
+		PlayerTurn(state S) int
+		// Finished reports whether the game is over.
+		Finished(state S) bool
+	}
+
+	// Runner plays games for the client's user.
+	Runner[S any] struct {
+		Client *client.BoardBotClient[S]
+		Rules  Rules[S]
+		Policy Policy[S]
+		// Logf reports progress, defaults to printing to stdout.
+		Logf func(format string, args ...any)
+
+		loginLock sync.Mutex
+		loggedIn  bool
+	}
+)
+
+func (f PolicyFunc[S]) ChooseMove(ctx context.Context, state S) (client.MoveT, error) {
+	return f(ctx, state)
+}
+
+func NewRunner[S any](bbClient *client.BoardBotClient[S], rules Rules[S], policy Policy[S]) *Runner[S] {
+	return &Runner[S]{
+		Client: bbClient,
+		Rules:  rules,
+		Policy: policy,
+		Logf: func(format string, args ...any) {
+			fmt.Printf(format+"\n", args...)
+		},
+	}
+}
+
+// Login authenticates the client. It is safe to call from many games at
+// once, only the first call logs in.
+func (r *Runner[S]) Login(ctx context.Context) error {
+	r.loginLock.Lock()
+	defer r.loginLock.Unlock()
+	if r.loggedIn {
+		return nil
+	}
+	if err := r.Client.Authenticate(ctx); err != nil {
+		return err
+	}
+	r.loggedIn = true
+	return nil
+}
+
+// relogin authenticates again after the session expired.
+func (r *Runner[S]) relogin(ctx context.Context) error {
+	r.loginLock.Lock()
+	r.loggedIn = false
+	r.loginLock.Unlock()
+	return r.Login(ctx)
+}
+
+// Play plays the game until it is over, returning the finished game. Play
+// returns early if the context is cancelled, the policy fails, or the
+// server rejects one of the policy's moves.
+func (r *Runner[S]) Play(ctx context.Context, gameId string) (client.Game[S], error) {
+	return r.play(ctx, ctx, gameId)
+}
+
+// play is Play, but it also returns between moves once stop is cancelled.
+// Cancelling ctx interrupts the move being made.
+func (r *Runner[S]) play(ctx, stop context.Context, gameId string) (client.Game[S], error) {
+	var game client.Game[S]
+	if err := r.Login(ctx); err != nil {
+		return game, err
+	}
+	game, err := r.Client.Game(ctx, gameId)
+	if err != nil {
+		return game, err
+	}
+	seat := Seat(game, r.Client.Credentials.Username)
+	if seat < 1 {
+		return game, fmt.Errorf("%s is not playing game %s", r.Client.Credentials.Username, gameId)
+	}
+
+	subscription, unsubscribe := context.WithCancel(ctx)
+	defer unsubscribe()
+	updates := r.Client.Subscribe(subscription, gameId)
+	reconnects := 0
+
+	for !game.Finished() && !r.Rules.Finished(game.State) {
+		if r.Rules.PlayerTurn(game.State) != seat {
+			var update client.GameUpdate[S]
+			var open bool
+			select {
+			case update, open = <-updates:
+			case <-stop.Done():
+				return game, stop.Err()
+			}
+			if !open {
+				if ctx.Err() != nil {
+					return game, ctx.Err()
+				}
+				// The subscription ended before the game did, reconnect. The
+				// game may have finished without a move, check before waiting.
+				game, err = r.Client.Game(ctx, gameId)
+				if err != nil {
+					return game, err
+				}
+				if game.Finished() || r.Rules.Finished(game.State) {
+					break
+				}
+				delay := reconnectDelay(reconnects)
+				reconnects++
+				r.Logf("game %s: reconnecting in %s", gameId, delay)
+				timer := time.NewTimer(delay)
+				select {
+				case <-timer.C:
+				case <-stop.Done():
+					timer.Stop()
+					return game, stop.Err()
+				}
+				updates = r.Client.Subscribe(subscription, gameId)
+				continue
+			}
+			if update.Err != nil {
+				r.Logf("game %s: error fetching game, %s", gameId, update.Err)
+				var apiErr *client.APIError
+				if errors.As(update.Err, &apiErr) && apiErr.IsAuthExpired() {
+					if err := r.relogin(ctx); err != nil {
+						return game, err
+					}
+				}
+				continue
+			}
+			reconnects = 0
+			// Our own moves are echoed back, skip those we've already seen.
+			if update.Game.NumMoves < game.NumMoves {
+				continue
+			}
+			game = update.Game
+			continue
+		}
+
+		if stop.Err() != nil {
+			return game, stop.Err()
+		}
+		move, err := r.Policy.ChooseMove(withStop(ctx, stop), game.State)
+		if err != nil {
+			return game, err
+		}
+		move.Player = seat
+		r.Logf("game %s: %s making move %+v", gameId, r.Client.Credentials.Username, move)
+		state, err := r.Client.MakeMove(ctx, gameId, client.MoveCommand{Json: move})
+		if err != nil {
+			game, err = r.resync(ctx, gameId, err)
+			if err != nil {
+				return game, err
+			}
+			continue
+		}
+		game.State = state
+		game.NumMoves++
+	}
+	return game, nil
+}
+
+// resync fetches the game again after a move failed. The move may or may
+// not have been applied. Returns the move's error if the server rejected
+// it, the policy and the server disagree on the rules.
+func (r *Runner[S]) resync(ctx context.Context, gameId string, moveErr error) (client.Game[S], error) {
+	var apiErr *client.APIError
+	isAPIErr := errors.As(moveErr, &apiErr)
+	if isAPIErr && apiErr.IsAuthExpired() {
+		if err := r.relogin(ctx); err != nil {
+			return client.Game[S]{}, err
+		}
+	}
+
+	game, err := r.Client.Game(ctx, gameId)
+	if err != nil {
+		return game, err
+	}
+	if game.Finished() || r.Rules.Finished(game.State) {
+		return game, nil
+	}
+	if isAPIErr && apiErr.IsIllegalMove() {
+		return game, moveErr
+	}
+	r.Logf("game %s: failed to make move, %s", gameId, moveErr)
+	return game, nil
+}
+
+// reconnectDelay is how long to wait before resubscribing to a game whose
+// subscription ended early for the n-th time in a row, doubling from
+// minReconnectDelay up to maxReconnectDelay.
+func reconnectDelay(n int) time.Duration {
+	delay := minReconnectDelay << n
+	if delay > maxReconnectDelay || delay <= 0 {
+		return maxReconnectDelay
+	}
+	return delay
+}
+
+// Seat returns the position of the user in the game, counting from 1, or
+// -1 if they aren't playing.
+func Seat[S any](game client.Game[S], username string) int {
+	for idx, user := range game.Players {
+		if user.Name == username {
+			return idx + 1
+		}
+	}
+	return -1
+}
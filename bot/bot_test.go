@@ -0,0 +1,162 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rwsargent/boardbots-go/client"
+	"github.com/rwsargent/boardbots-go/client/fakeserver"
+	"github.com/rwsargent/boardbots-go/lockitdown"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRunner(t *testing.T, server *fakeserver.Server, username string, policy Policy[lockitdown.TransportState]) *Runner[lockitdown.TransportState] {
+	bbClient, err := client.NewBoardBotClient[lockitdown.TransportState](client.Credentials{Username: username}, server.URL)
+	assert.Nil(t, err)
+	runner := NewRunner(bbClient, LockItDown, policy)
+	runner.Logf = t.Logf
+	return runner
+}
+
+// startTestGame starts a game hosted by host against oppo.
+func startTestGame(t *testing.T, server *fakeserver.Server, host, oppo *Runner[lockitdown.TransportState]) string {
+	ctx := context.Background()
+	assert.Nil(t, host.Login(ctx))
+	assert.Nil(t, oppo.Login(ctx))
+	gameId, err := server.StartGame(host.Client.Credentials.Username, oppo.Client.Credentials.Username)
+	assert.Nil(t, err)
+	return gameId.String()
+}
+
+func TestPlay(t *testing.T) {
+	server := fakeserver.New(fakeserver.DefaultGameDef)
+	defer server.Close()
+
+	var gameId string
+	calls := 0
+	random := NewRandom(7)
+	// The host resigns on its seventh move, by making the opponent win.
+	resigning := PolicyFunc[lockitdown.TransportState](func(ctx context.Context, state lockitdown.TransportState) (client.MoveT, error) {
+		calls++
+		if calls == 7 {
+			id := uuid.MustParse(gameId)
+			game := server.GameState(id)
+			game.Winner = 1
+			assert.Nil(t, server.SetGameState(id, game))
+		}
+		return random.ChooseMove(ctx, state)
+	})
+
+	host := newTestRunner(t, server, "host", resigning)
+	oppo := newTestRunner(t, server, "oppo", NewRandom(11))
+	gameId = startTestGame(t, server, host, oppo)
+
+	var wg sync.WaitGroup
+	for _, runner := range []*Runner[lockitdown.TransportState]{host, oppo} {
+		wg.Add(1)
+		go func(runner *Runner[lockitdown.TransportState]) {
+			defer wg.Done()
+			game, err := runner.Play(context.Background(), gameId)
+			assert.Nil(t, err)
+			assert.Equal(t, lockitdown.Status("1"), game.State.Status)
+		}(runner)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 7, calls)
+	history, err := host.Client.MoveHistory(context.Background(), gameId)
+	assert.Nil(t, err)
+	hostMoves := 0
+	for _, move := range history {
+		if move.Player.Username == "host" {
+			hostMoves++
+		}
+	}
+	// The last move was made after the game was over.
+	assert.Equal(t, 6, hostMoves)
+}
+
+func TestPlayIllegalMove(t *testing.T) {
+	server := fakeserver.New(fakeserver.DefaultGameDef)
+	defer server.Close()
+
+	illegal := PolicyFunc[lockitdown.TransportState](func(ctx context.Context, state lockitdown.TransportState) (client.MoveT, error) {
+		return client.MoveT{Action: "Advance"}, nil
+	})
+	host := newTestRunner(t, server, "host", illegal)
+	oppo := newTestRunner(t, server, "oppo", NewRandom(11))
+	gameId := startTestGame(t, server, host, oppo)
+
+	_, err := host.Play(context.Background(), gameId)
+	var apiErr *client.APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.True(t, apiErr.IsIllegalMove())
+}
+
+func TestPlayNotSeated(t *testing.T) {
+	server := fakeserver.New(fakeserver.DefaultGameDef)
+	defer server.Close()
+
+	host := newTestRunner(t, server, "host", NewRandom(7))
+	oppo := newTestRunner(t, server, "oppo", NewRandom(11))
+	gameId := startTestGame(t, server, host, oppo)
+
+	_, err := newTestRunner(t, server, "stranger", NewRandom(13)).Play(context.Background(), gameId)
+	assert.NotNil(t, err)
+}
+
+func TestPlayCancelled(t *testing.T) {
+	server := fakeserver.New(fakeserver.DefaultGameDef)
+	defer server.Close()
+
+	host := newTestRunner(t, server, "host", NewRandom(7))
+	oppo := newTestRunner(t, server, "oppo", NewRandom(11))
+	gameId := startTestGame(t, server, host, oppo)
+
+	// The host never moves, so the opponent waits until it is cancelled.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := oppo.Play(ctx, gameId)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestPlayFinishedByServer(t *testing.T) {
+	server := fakeserver.New(fakeserver.DefaultGameDef)
+	defer server.Close()
+
+	host := newTestRunner(t, server, "host", NewRandom(7))
+	oppo := newTestRunner(t, server, "oppo", NewRandom(11))
+	gameId := startTestGame(t, server, host, oppo)
+
+	// The host never moves, the server ends the game while the opponent
+	// waits. The state still has no winner.
+	time.AfterFunc(50*time.Millisecond, func() {
+		assert.Nil(t, server.FinishGame(uuid.MustParse(gameId)))
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	game, err := oppo.Play(ctx, gameId)
+	assert.Nil(t, err)
+	assert.True(t, game.Finished())
+	assert.False(t, LockItDown.Finished(game.State))
+}
+
+func TestReconnectDelay(t *testing.T) {
+	assert.Equal(t, minReconnectDelay, reconnectDelay(0))
+	assert.Equal(t, 2*minReconnectDelay, reconnectDelay(1))
+	assert.Equal(t, maxReconnectDelay, reconnectDelay(20))
+	assert.Equal(t, maxReconnectDelay, reconnectDelay(100))
+}
+
+func TestSeat(t *testing.T) {
+	game := client.Game[lockitdown.TransportState]{
+		Players: []client.User{{Name: "host"}, {Name: "oppo"}},
+	}
+	assert.Equal(t, 1, Seat(game, "host"))
+	assert.Equal(t, 2, Seat(game, "oppo"))
+	assert.Equal(t, -1, Seat(game, "stranger"))
+}
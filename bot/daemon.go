@@ -0,0 +1,219 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/rwsargent/boardbots-go/client"
+)
+
+// daemon.go keeps a bot playing every game it is in, for as long as it runs.
+
+type (
+	// Daemon plays all of the account's active games concurrently, each in
+	// its own goroutine, and looks for new ones every PollInterval.
+	Daemon[S any] struct {
+		Runner *Runner[S]
+		// PollInterval is the time between looking for new games.
+		PollInterval time.Duration
+		// JoinLobby decides which open lobbies to join. Nil joins none, the
+		// daemon only plays games it was put into.
+		JoinLobby func(lobby client.Lobby) bool
+
+		// games are the games being played, and joined the open lobbies
+		// already tried.
+		lock   sync.Mutex
+		games  map[string]bool
+		joined map[string]bool
+	}
+
+	// limitedPolicy lets at most cap(slots) moves be chosen at once.
+	limitedPolicy[S any] struct {
+		policy Policy[S]
+		slots  chan struct{}
+	}
+
+	// stopKey is the context key of the context that stops the runner
+	// between moves.
+	stopKey struct{}
+)
+
+// NewDaemon plays games with the runner's client, rules and policy,
+// searching for at most as many moves at once as there are CPUs. The runner
+// is left as it was.
+func NewDaemon[S any](runner *Runner[S]) *Daemon[S] {
+	return &Daemon[S]{
+		Runner: &Runner[S]{
+			Client: runner.Client,
+			Rules:  runner.Rules,
+			Policy: Limit(runner.Policy, runtime.GOMAXPROCS(0)),
+			Logf:   runner.Logf,
+		},
+		PollInterval: 10 * time.Second,
+		games:        make(map[string]bool),
+		joined:       make(map[string]bool),
+	}
+}
+
+// Limit wraps a policy so at most n moves are chosen at once. Games waiting
+// for their turn to search are queued, until the runner stops.
+func Limit[S any](policy Policy[S], n int) Policy[S] {
+	return limitedPolicy[S]{
+		policy: policy,
+		slots:  make(chan struct{}, n),
+	}
+}
+
+func (p limitedPolicy[S]) ChooseMove(ctx context.Context, state S) (client.MoveT, error) {
+	stop := stopContext(ctx)
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return client.MoveT{}, ctx.Err()
+	case <-stop.Done():
+		return client.MoveT{}, stop.Err()
+	}
+	defer func() { <-p.slots }()
+	// The slot may have been free when the runner stopped.
+	if err := stop.Err(); err != nil {
+		return client.MoveT{}, err
+	}
+	return p.policy.ChooseMove(ctx, state)
+}
+
+// withStop tells the policies choosing a move with ctx that the runner
+// stops between moves once stop is cancelled, so they don't start moves
+// it won't make.
+func withStop(ctx, stop context.Context) context.Context {
+	return context.WithValue(ctx, stopKey{}, stop)
+}
+
+// stopContext is the context given to withStop, or ctx itself.
+func stopContext(ctx context.Context) context.Context {
+	if stop, ok := ctx.Value(stopKey{}).(context.Context); ok {
+		return stop
+	}
+	return ctx
+}
+
+// Run plays games until the context is cancelled. It then stops looking for
+// games, and returns once every game has finished the move it is making.
+func (d *Daemon[S]) Run(ctx context.Context) error {
+	if err := d.Runner.Login(ctx); err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	ticker := time.NewTicker(d.PollInterval)
+	defer ticker.Stop()
+	for {
+		d.joinLobbies(ctx)
+		d.startGames(ctx, &wg)
+
+		select {
+		case <-ctx.Done():
+			d.Runner.Logf("shutting down, finishing in-flight moves")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *Daemon[S]) joinLobbies(ctx context.Context) {
+	if d.JoinLobby == nil {
+		return
+	}
+	lobbies, err := d.Runner.Client.Lobbies(ctx)
+	if err != nil {
+		d.Runner.Logf("failed to list lobbies, %s", err)
+		return
+	}
+	// Lobbies that aren't open anymore won't come up again.
+	open := make(map[string]bool, len(lobbies))
+	for _, lobby := range lobbies {
+		open[lobby.Id.String()] = true
+	}
+	for id := range d.joined {
+		if !open[id] {
+			delete(d.joined, id)
+		}
+	}
+
+	for _, lobby := range lobbies {
+		id := lobby.Id.String()
+		if d.joined[id] || lobby.Host.Name == d.Runner.Client.Credentials.Username || !d.JoinLobby(lobby) {
+			continue
+		}
+		// Lobbies are only tried once, they fill up or close while we look.
+		d.joined[id] = true
+		if _, err := d.Runner.Client.JoinLobby(ctx, id); err != nil {
+			d.Runner.Logf("failed to join lobby %s, %s", id, err)
+			continue
+		}
+		d.Runner.Logf("joined lobby %s", id)
+	}
+}
+
+// startGames starts playing the active games that aren't being played yet.
+func (d *Daemon[S]) startGames(ctx context.Context, wg *sync.WaitGroup) {
+	games, err := d.Runner.Client.Games(ctx, client.ActiveGames)
+	if err != nil {
+		d.Runner.Logf("failed to list games, %s", err)
+		return
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	for _, game := range games {
+		id := game.Id.String()
+		if d.games[id] {
+			continue
+		}
+		d.games[id] = true
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.play(ctx, id)
+		}()
+	}
+}
+
+// play plays a game until it is over or the daemon stops. Moves that are
+// being made when the daemon stops are finished.
+func (d *Daemon[S]) play(stop context.Context, gameId string) {
+	d.Runner.Logf("game %s: playing", gameId)
+	_, err := d.Runner.play(context.Background(), stop, gameId)
+	if stop.Err() != nil {
+		return
+	}
+	if err == nil {
+		d.Runner.Logf("game %s: game over", gameId)
+		d.lock.Lock()
+		delete(d.games, gameId)
+		d.lock.Unlock()
+		return
+	}
+
+	d.Runner.Logf("game %s: stopped playing, %s", gameId, err)
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) && apiErr.IsIllegalMove() {
+		// The policy would make the same move again.
+		return
+	}
+	// Try the game again the next time games are listed.
+	d.lock.Lock()
+	delete(d.games, gameId)
+	d.lock.Unlock()
+}
+
+// GameType joins lobbies for games of the given type.
+func GameType(gameType string) func(lobby client.Lobby) bool {
+	return func(lobby client.Lobby) bool {
+		return lobby.GameType == gameType
+	}
+}
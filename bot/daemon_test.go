@@ -0,0 +1,207 @@
+package bot
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rwsargent/boardbots-go/client"
+	"github.com/rwsargent/boardbots-go/client/fakeserver"
+	"github.com/rwsargent/boardbots-go/lockitdown"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDaemon(t *testing.T) {
+	server := fakeserver.New(fakeserver.DefaultGameDef)
+	defer server.Close()
+
+	host := newTestRunner(t, server, "host", NewRandom(7))
+	oppo := newTestRunner(t, server, "oppo", NewRandom(11))
+	ctx := context.Background()
+	assert.Nil(t, host.Login(ctx))
+
+	lobbies := make([]client.Lobby, 3)
+	for i := range lobbies {
+		lobby, err := host.Client.CreateLobby(ctx, client.LobbyOptions{GameType: "lockitdown"})
+		assert.Nil(t, err)
+		lobbies[i] = lobby
+	}
+
+	hostDaemon := NewDaemon(host)
+	hostDaemon.PollInterval = 10 * time.Millisecond
+	oppoDaemon := NewDaemon(oppo)
+	oppoDaemon.PollInterval = 10 * time.Millisecond
+	oppoDaemon.JoinLobby = GameType("lockitdown")
+
+	running, stop := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	for _, daemon := range []*Daemon[lockitdown.TransportState]{hostDaemon, oppoDaemon} {
+		wg.Add(1)
+		go func(daemon *Daemon[lockitdown.TransportState]) {
+			defer wg.Done()
+			assert.Nil(t, daemon.Run(running))
+		}(daemon)
+	}
+
+	games := make([]string, len(lobbies))
+	for i, lobby := range lobbies {
+		assert.Eventually(t, func() bool {
+			joined, err := host.Client.Lobby(ctx, lobby.Id.String())
+			return err == nil && len(joined.Players) == 2
+		}, time.Second, 5*time.Millisecond)
+		game, err := host.Client.StartGame(ctx, lobby.Id.String())
+		assert.Nil(t, err)
+		games[i] = game.Id.String()
+	}
+
+	// Every game is played at once.
+	for _, gameId := range games {
+		assert.Eventually(t, func() bool {
+			history, err := host.Client.MoveHistory(ctx, gameId)
+			return err == nil && len(history) > 4
+		}, 5*time.Second, 5*time.Millisecond)
+	}
+
+	stop()
+	wg.Wait()
+
+	// No moves are made after the daemons stop.
+	before := make([]int, len(games))
+	for i, gameId := range games {
+		history, err := host.Client.MoveHistory(ctx, gameId)
+		assert.Nil(t, err)
+		before[i] = len(history)
+	}
+	time.Sleep(50 * time.Millisecond)
+	for i, gameId := range games {
+		history, err := host.Client.MoveHistory(ctx, gameId)
+		assert.Nil(t, err)
+		assert.Equal(t, before[i], len(history))
+	}
+}
+
+func TestDaemonForgetsFinishedGames(t *testing.T) {
+	server := fakeserver.New(fakeserver.DefaultGameDef)
+	defer server.Close()
+
+	host := newTestRunner(t, server, "host", NewRandom(7))
+	oppo := newTestRunner(t, server, "oppo", NewRandom(11))
+	policy := host.Policy
+	daemon := NewDaemon(host)
+	daemon.PollInterval = 10 * time.Millisecond
+	assert.Equal(t, policy, host.Policy, "the daemon changed the runner")
+
+	gameId := startTestGame(t, server, host, oppo)
+	playing := func() int {
+		daemon.lock.Lock()
+		defer daemon.lock.Unlock()
+		return len(daemon.games)
+	}
+
+	running, stop := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.Nil(t, daemon.Run(running))
+	}()
+	assert.Eventually(t, func() bool { return playing() == 1 }, time.Second, 5*time.Millisecond)
+
+	id, err := uuid.Parse(gameId)
+	assert.Nil(t, err)
+	won := server.GameState(id)
+	won.Winner = 1
+	assert.Nil(t, server.SetGameState(id, won))
+	assert.Eventually(t, func() bool { return playing() == 0 }, time.Second, 5*time.Millisecond)
+
+	stop()
+	<-done
+}
+
+func TestDaemonStopsQueuedMoves(t *testing.T) {
+	server := fakeserver.New(fakeserver.DefaultGameDef)
+	defer server.Close()
+
+	var lock sync.Mutex
+	searches := 0
+	release := make(chan struct{})
+	slow := PolicyFunc[lockitdown.TransportState](func(ctx context.Context, state lockitdown.TransportState) (client.MoveT, error) {
+		lock.Lock()
+		searches++
+		lock.Unlock()
+		<-release
+		return NewRandom(7).ChooseMove(ctx, state)
+	})
+	started := func() int {
+		lock.Lock()
+		defer lock.Unlock()
+		return searches
+	}
+
+	host := newTestRunner(t, server, "host", slow)
+	daemon := NewDaemon(host)
+	daemon.Runner.Policy = Limit(host.Policy, 1)
+	daemon.PollInterval = 10 * time.Millisecond
+	for i := 0; i < 3; i++ {
+		_, err := server.StartGame("host", "oppo")
+		assert.Nil(t, err)
+	}
+
+	running, stop := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.Nil(t, daemon.Run(running))
+	}()
+	// One game searches, the other two wait for the slot.
+	assert.Eventually(t, func() bool {
+		daemon.lock.Lock()
+		defer daemon.lock.Unlock()
+		return started() == 1 && len(daemon.games) == 3
+	}, time.Second, 5*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+
+	stop()
+	close(release)
+	<-done
+	assert.Equal(t, 1, started(), "queued moves were searched after the daemon stopped")
+}
+
+func TestLimit(t *testing.T) {
+	var lock sync.Mutex
+	searching, most := 0, 0
+	slow := PolicyFunc[int](func(ctx context.Context, state int) (client.MoveT, error) {
+		lock.Lock()
+		searching++
+		if searching > most {
+			most = searching
+		}
+		lock.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		lock.Lock()
+		searching--
+		lock.Unlock()
+		return client.MoveT{Player: state}, nil
+	})
+
+	limited := Limit[int](slow, 2)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			move, err := limited.ChooseMove(context.Background(), i)
+			assert.Nil(t, err)
+			assert.Equal(t, i, move.Player)
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 2, most)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	full := Limit[int](slow, 0)
+	_, err := full.ChooseMove(ctx, 0)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,109 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/rwsargent/boardbots-go/client"
+	"github.com/rwsargent/boardbots-go/internal"
+	"github.com/rwsargent/boardbots-go/lockitdown"
+)
+
+// lockitdown.go has the rules of lockitdown games, and policies to play them.
+
+type (
+	lockItDownRules struct{}
+
+	// Minimax searches for the best move with alpha-beta pruning, deepening
+	// the search until Depth, or until MoveTime passes.
+	Minimax struct {
+		Depth     int
+		MoveTime  time.Duration
+		Evaluator lockitdown.Evaluator
+	}
+
+	// Random plays uniformly random legal moves.
+	Random struct {
+		lock sync.Mutex
+		rng  *rand.Rand
+	}
+)
+
+// LockItDown are the Rules of lockitdown games.
+var LockItDown Rules[lockitdown.TransportState] = lockItDownRules{}
+
+var errNoMoves = errors.New("no legal moves")
+
+func (lockItDownRules) PlayerTurn(state lockitdown.TransportState) int {
+	return state.PlayerTurn
+}
+
+func (lockItDownRules) Finished(state lockitdown.TransportState) bool {
+	return state.Status != lockitdown.OnGoing
+}
+
+// LockItDownMove converts a move to the wire format of boardbots.dev.
+func LockItDownMove(move lockitdown.GameMove) client.MoveT {
+	movet := move.ToTransport()
+	return client.MoveT{
+		Player: int(move.Player) + 1,
+		Pos: internal.Position{
+			Q: movet.Position.Q,
+			R: movet.Position.R,
+		},
+		Action: movet.Action,
+	}
+}
+
+// LockItDownGameMove reads a move in the wire format of boardbots.dev, like
+// those listed by client.GetPossibleMoves.
+func LockItDownGameMove(move client.MoveT) (lockitdown.GameMove, error) {
+	return lockitdown.MoveFromTransport(lockitdown.BoardbotsMove{
+		Position: lockitdown.Pair{Q: move.Pos.Q, R: move.Pos.R},
+		Action:   move.Action,
+	}, lockitdown.PlayerPosition(move.Player-1))
+}
+
+func (m Minimax) ChooseMove(ctx context.Context, state lockitdown.TransportState) (client.MoveT, error) {
+	move, _, err := m.ChooseMoveStats(ctx, state)
+	return move, err
+}
+
+func (m Minimax) ChooseMoveStats(ctx context.Context, state lockitdown.TransportState) (client.MoveT, SearchStats, error) {
+	result := lockitdown.Search(ctx, lockitdown.StateFromTransport(&state), lockitdown.SearchOptions{
+		Evaluator: m.Evaluator,
+		MaxDepth:  m.Depth,
+		MoveTime:  m.MoveTime,
+	})
+	stats := SearchStats{
+		Depth:       result.Depth,
+		Nodes:       result.Nodes,
+		TableProbes: result.TableProbes,
+		TableHits:   result.TableHits,
+	}
+	if ctx.Err() != nil {
+		return client.MoveT{}, stats, ctx.Err()
+	}
+	if result.Move.Mover == nil {
+		return client.MoveT{}, stats, errNoMoves
+	}
+	return LockItDownMove(result.Move), stats, nil
+}
+
+func NewRandom(seed int64) *Random {
+	return &Random{rng: rand.New(rand.NewSource(seed))}
+}
+
+func (r *Random) ChooseMove(ctx context.Context, state lockitdown.TransportState) (client.MoveT, error) {
+	moves := lockitdown.StateFromTransport(&state).PossibleMoves(nil)
+	if len(moves) == 0 {
+		return client.MoveT{}, errNoMoves
+	}
+	r.lock.Lock()
+	move := moves[r.rng.Intn(len(moves))]
+	r.lock.Unlock()
+	return LockItDownMove(move), nil
+}
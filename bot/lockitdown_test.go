@@ -0,0 +1,47 @@
+package bot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rwsargent/boardbots-go/client"
+	"github.com/rwsargent/boardbots-go/client/fakeserver"
+	"github.com/rwsargent/boardbots-go/lockitdown"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockItDownGameMove(t *testing.T) {
+	server := fakeserver.New(fakeserver.DefaultGameDef)
+	defer server.Close()
+
+	host := newTestRunner(t, server, "host", NewRandom(7))
+	oppo := newTestRunner(t, server, "oppo", NewRandom(11))
+	gameId := startTestGame(t, server, host, oppo)
+	ctx := context.Background()
+
+	for i := 0; i < 4; i++ {
+		game := server.GameState(uuid.MustParse(gameId))
+		serverMoves, err := host.Client.GetPossibleMoves(ctx, gameId)
+		assert.Nil(t, err)
+
+		decoded := make([]lockitdown.GameMove, 0, len(serverMoves))
+		for _, serverMove := range serverMoves {
+			move, err := LockItDownGameMove(serverMove)
+			assert.Nil(t, err)
+			assert.Equal(t, serverMove.Player, LockItDownMove(move).Player)
+			again, err := LockItDownGameMove(LockItDownMove(move))
+			assert.Nil(t, err)
+			assert.Equal(t, move, again)
+			decoded = append(decoded, move)
+		}
+		assert.ElementsMatch(t, game.PossibleMoves(nil), decoded)
+
+		runner := host
+		if game.PlayerTurn == 1 {
+			runner = oppo
+		}
+		_, err = runner.Client.MakeMove(ctx, gameId, client.MoveCommand{Json: serverMoves[0]})
+		assert.Nil(t, err)
+	}
+}
@@ -0,0 +1,82 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rwsargent/boardbots-go/client"
+	"github.com/rwsargent/boardbots-go/metrics"
+)
+
+// metrics.go measures how a policy plays.
+
+type measuredPolicy[S any] struct {
+	policy   Policy[S]
+	moves    *metrics.Counter
+	duration *metrics.Histogram
+	errors   *metrics.Counter
+
+	depth          *metrics.Histogram
+	nodesPerSecond *metrics.Histogram
+	tableProbes    *metrics.Counter
+	tableHits      *metrics.Counter
+}
+
+// Measure wraps a policy to count the moves it chooses, the time it takes
+// to choose them, and its errors, in the registry. The searches of
+// Searchers are measured too: their depth, speed and transposition table
+// hits.
+func Measure[S any](policy Policy[S], registry *metrics.Registry) Policy[S] {
+	return measuredPolicy[S]{
+		policy: policy,
+		moves:  registry.Counter("bot_moves_total", "Moves chosen by the policy."),
+		duration: registry.Histogram("bot_move_duration_seconds",
+			"Time to choose a move.", metrics.DurationBuckets),
+		errors: registry.Counter("bot_errors_total",
+			"Moves the policy failed to choose, by type of error.", "type"),
+		depth: registry.Histogram("bot_search_depth",
+			"Deepest completed search of each move.", metrics.LinearBuckets(1, 1, 10)),
+		nodesPerSecond: registry.Histogram("bot_search_nodes_per_second",
+			"Positions searched per second.", metrics.ExponentialBuckets(10_000, 2, 10)),
+		tableProbes: registry.Counter("bot_search_table_probes_total",
+			"Lookups in the transposition table. The hit rate is bot_search_table_hits_total over this."),
+		tableHits: registry.Counter("bot_search_table_hits_total",
+			"Lookups in the transposition table that found a position searched before."),
+	}
+}
+
+func (p measuredPolicy[S]) ChooseMove(ctx context.Context, state S) (client.MoveT, error) {
+	start := time.Now()
+	var move client.MoveT
+	var err error
+	if searcher, ok := p.policy.(Searcher[S]); ok {
+		var stats SearchStats
+		move, stats, err = searcher.ChooseMoveStats(ctx, state)
+		p.observeSearch(stats, time.Since(start))
+	} else {
+		move, err = p.policy.ChooseMove(ctx, state)
+	}
+	switch {
+	case err == nil:
+		p.moves.Inc()
+		p.duration.Observe(time.Since(start).Seconds())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		p.errors.Inc("cancelled")
+	case errors.Is(err, errNoMoves):
+		p.errors.Inc("no_moves")
+	default:
+		p.errors.Inc("policy")
+	}
+	return move, err
+}
+
+// observeSearch records how a search went.
+func (p measuredPolicy[S]) observeSearch(stats SearchStats, elapsed time.Duration) {
+	p.depth.Observe(float64(stats.Depth))
+	if elapsed > 0 {
+		p.nodesPerSecond.Observe(float64(stats.Nodes) / elapsed.Seconds())
+	}
+	p.tableProbes.Add(float64(stats.TableProbes))
+	p.tableHits.Add(float64(stats.TableHits))
+}
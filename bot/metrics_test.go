@@ -0,0 +1,56 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/rwsargent/boardbots-go/client"
+	"github.com/rwsargent/boardbots-go/lockitdown"
+	"github.com/rwsargent/boardbots-go/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMeasure(t *testing.T) {
+	errs := []error{nil, nil, errNoMoves, context.Canceled, errors.New("broken")}
+	policy := PolicyFunc[int](func(ctx context.Context, state int) (client.MoveT, error) {
+		return client.MoveT{Player: state}, errs[state]
+	})
+	registry := metrics.NewRegistry()
+	measured := Measure[int](policy, registry)
+
+	for state := range errs {
+		move, err := measured.ChooseMove(context.Background(), state)
+		assert.Equal(t, errs[state], err)
+		assert.Equal(t, state, move.Player)
+	}
+
+	var out strings.Builder
+	_, err := registry.WriteTo(&out)
+	assert.Nil(t, err)
+	assert.Contains(t, out.String(), "bot_moves_total 2\n")
+	assert.Contains(t, out.String(), "bot_move_duration_seconds_count 2\n")
+	assert.Contains(t, out.String(), `bot_errors_total{type="cancelled"} 1`)
+	assert.Contains(t, out.String(), `bot_errors_total{type="no_moves"} 1`)
+	assert.Contains(t, out.String(), `bot_errors_total{type="policy"} 1`)
+}
+
+func TestMeasureSearch(t *testing.T) {
+	registry := metrics.NewRegistry()
+	measured := Measure[lockitdown.TransportState](Minimax{Depth: 2}, registry)
+	state := lockitdown.ConvertToTransport(lockitdown.NewGame(lockitdown.DefaultGameDef))
+
+	_, err := measured.ChooseMove(context.Background(), *state)
+	assert.Nil(t, err)
+
+	var out strings.Builder
+	_, err = registry.WriteTo(&out)
+	assert.Nil(t, err)
+	assert.Contains(t, out.String(), "bot_moves_total 1\n")
+	assert.Contains(t, out.String(), `bot_search_depth_bucket{le="2"} 1`)
+	assert.Contains(t, out.String(), `bot_search_depth_bucket{le="1"} 0`)
+	assert.Contains(t, out.String(), "bot_search_nodes_per_second_count 1\n")
+	assert.Contains(t, out.String(), "bot_search_table_probes_total ")
+	assert.Contains(t, out.String(), "bot_search_table_hits_total ")
+}
@@ -4,8 +4,8 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/cookiejar"
@@ -16,11 +16,11 @@ import (
 
 type (
 	Client interface {
-		Authenticate() error
-		MakeMove() error
-		StartGame() error
-		JoinLobby() error
-		CreateLobby() error
+		Authenticate(ctx context.Context) error
+		MakeMove(ctx context.Context) error
+		StartGame(ctx context.Context) error
+		JoinLobby(ctx context.Context) error
+		CreateLobby(ctx context.Context, opts LobbyOptions) error
 	}
 
 	Credentials struct {
@@ -28,9 +28,14 @@ type (
 	}
 	BoardBotClient[State any] struct {
 		Credentials Credentials
-		httpClient  *http.Client
-		domain      string
-		user        User
+		// RequestTimeout bounds each attempt of a request. Long lived requests,
+		// like subscriptions, are only bounded by their context.
+		RequestTimeout time.Duration
+		// Retry controls how idempotent requests are retried.
+		Retry      RetryPolicy
+		httpClient *http.Client
+		domain     string
+		user       User
 	}
 
 	User struct {
@@ -49,10 +54,11 @@ func NewBoardBotClient[State any](creds Credentials, addr string) (*BoardBotClie
 	}
 
 	return &BoardBotClient[State]{
-		Credentials: creds,
+		Credentials:    creds,
+		RequestTimeout: time.Duration(2) * time.Second,
+		Retry:          DefaultRetryPolicy,
 		httpClient: &http.Client{
-			Jar:     jar,
-			Timeout: time.Duration(2) * time.Second,
+			Jar: jar,
 		},
 		domain: addr,
 	}, nil
@@ -60,54 +66,33 @@ func NewBoardBotClient[State any](creds Credentials, addr string) (*BoardBotClie
 
 // Authenticates the client. Stores auth cookie automatically, makes
 // Another call to get user information.
-func (c *BoardBotClient[S]) Authenticate() error {
-	resp, err := c.httpClient.Get(c.domain + "/auth/login?name=" + c.Credentials.Username)
+func (c *BoardBotClient[S]) Authenticate(ctx context.Context) error {
+	_, err := GetString(ctx, c, "/auth/login?name="+c.Credentials.Username)
 	if err != nil {
 		return err
 	}
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("status code: %d", resp.StatusCode)
-	}
-	resp, err = c.httpClient.Get(c.domain + "/user")
-	if err != nil {
-		return err
-	}
-	err = json.NewDecoder(resp.Body).Decode(&c.user)
-	defer resp.Body.Close()
-
+	user, err := GetString(ctx, c, "/user")
 	if err != nil {
 		return err
 	}
-
-	return nil
+	return json.Unmarshal([]byte(user), &c.user)
 }
 
 // Post makes an HTTP post call with the supplied request, and Unmarshals
-// the response from JSON to the supplied request struct.
-func Post[Req any, Res any, S any](bbClient *BoardBotClient[S], path string, body Req) (Res, error) {
+// the response from JSON to the supplied request struct. Posts are not
+// idempotent, and are never retried.
+func Post[Req any, Res any, S any](ctx context.Context, bbClient *BoardBotClient[S], path string, body Req) (Res, error) {
 	b, err := json.Marshal(body)
 	var resp Res
 	if err != nil {
 		return resp, err
 	}
 
-	req, err := http.NewRequest("POST", bbClient.domain+path, bytes.NewBuffer(b))
-	if err != nil {
-		return resp, err
-	}
-
-	httpResponse, err := bbClient.httpClient.Do(req)
+	responseBody, err := bbClient.do(ctx, "POST", path, b)
 	if err != nil {
 		return resp, err
 	}
 
-	responseBody, _ := ioutil.ReadAll(httpResponse.Body)
-	defer httpResponse.Body.Close()
-
-	if httpResponse.StatusCode != 200 {
-		return resp, fmt.Errorf("response status: %d. Response body: %s", httpResponse.StatusCode, string(responseBody))
-	}
-
 	if len(responseBody) == 0 {
 		return resp, nil
 	}
@@ -124,12 +109,12 @@ func Post[Req any, Res any, S any](bbClient *BoardBotClient[S], path string, bod
 }
 
 // Get makes an HTTP Get call, and Unmarshals the response to the provided struct.
-func Get[Res any, S any](client *BoardBotClient[S], path string) (Res, error) {
-	body, err := GetString(client, path)
+func Get[Res any, S any](ctx context.Context, client *BoardBotClient[S], path string) (Res, error) {
+	body, err := GetString(ctx, client, path)
 
 	var resp Res
 	if err != nil {
-		return resp, nil
+		return resp, err
 	}
 
 	err = json.Unmarshal([]byte(body), &resp)
@@ -140,22 +125,53 @@ func Get[Res any, S any](client *BoardBotClient[S], path string) (Res, error) {
 }
 
 // GetString makes an HTTP GET call, and returns the body of the response
-// as a string
-func GetString[S any](client *BoardBotClient[S], path string) (string, error) {
-	httpResp, err := client.httpClient.Get(client.domain + path)
-	if err != nil {
-		return "", err
+// as a string. Failed calls are retried according to the client's
+// RetryPolicy.
+func GetString[S any](ctx context.Context, client *BoardBotClient[S], path string) (string, error) {
+	var body []byte
+	err := client.Retry.retry(ctx, func() error {
+		var err error
+		body, err = client.do(ctx, "GET", path, nil)
+		return err
+	})
+	return string(body), err
+}
+
+// do makes a single attempt at a request, returning the response body of
+// successful requests.
+func (c *BoardBotClient[S]) do(ctx context.Context, method, path string, body []byte) ([]byte, error) {
+	if c.RequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.RequestTimeout)
+		defer cancel()
 	}
+	return c.roundTrip(ctx, method, path, body)
+}
 
-	body, err := ioutil.ReadAll(httpResp.Body)
-	defer httpResp.Body.Close()
+// roundTrip makes a request bounded only by the context.
+func (c *BoardBotClient[S]) roundTrip(ctx context.Context, method, path string, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.domain+path, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
 
-	if httpResp.StatusCode != 200 {
-		return "", fmt.Errorf("status code: %d, %s", httpResp.StatusCode, body)
+	httpResponse, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, &Error{Kind: NetworkError, Err: err}
 	}
+	defer httpResponse.Body.Close()
 
+	responseBody, err := ioutil.ReadAll(httpResponse.Body)
 	if err != nil {
-		return "", err
+		return nil, &Error{Kind: NetworkError, Err: err}
 	}
-	return string(body), err
+
+	if httpResponse.StatusCode != 200 {
+		return nil, &Error{
+			Kind:       kindOfStatus(httpResponse.StatusCode),
+			Err:        newAPIError(method, path, httpResponse.StatusCode, responseBody),
+			retryAfter: parseRetryAfter(httpResponse.Header.Get("Retry-After")),
+		}
+	}
+	return responseBody, nil
 }
@@ -1,33 +1,240 @@
 package client
 
 import (
-	"fmt"
+	"context"
 	"testing"
 
+	"github.com/rwsargent/boardbots-go/client/fakeserver"
+	"github.com/rwsargent/boardbots-go/internal"
 	"github.com/rwsargent/boardbots-go/lockitdown"
+	"github.com/stretchr/testify/assert"
 )
 
-func TestMoves(t *testing.T) {
+var lockitdownLobby = LobbyOptions{GameType: "lockitdown"}
 
+func newTestClient(t *testing.T, server *fakeserver.Server, username string) *BoardBotClient[lockitdown.TransportState] {
 	bbClient, err := NewBoardBotClient[lockitdown.TransportState](Credentials{
-		Username: "tester",
-	}, "http://localhost:8080")
+		Username: username,
+	}, server.URL)
+	assert.Nil(t, err)
+	assert.Nil(t, bbClient.Authenticate(context.Background()))
+	return bbClient
+}
 
-	if err != nil {
-		fmt.Printf("failed to start client, %s\n", err.Error())
-		return
-	}
+// startTestGame starts a game between a host and an opponent, returning
+// their clients and the started game.
+func startTestGame(t *testing.T, server *fakeserver.Server) (host, oppo *BoardBotClient[lockitdown.TransportState], game Game[lockitdown.TransportState]) {
+	gameId, err := server.StartGame("host", "oppo")
+	assert.Nil(t, err)
+	host = newTestClient(t, server, "host")
+	oppo = newTestClient(t, server, "oppo")
+
+	game, err = host.Game(context.Background(), gameId.String())
+	assert.Nil(t, err)
+	return host, oppo, game
+}
+
+func TestAuthenticate(t *testing.T) {
+	server := fakeserver.New(fakeserver.DefaultGameDef)
+	defer server.Close()
+
+	bbClient := newTestClient(t, server, "tester")
+	assert.Equal(t, "tester", bbClient.user.Name)
+	assert.Equal(t, "1", bbClient.user.Id.String())
+}
+
+func TestLobby(t *testing.T) {
+	server := fakeserver.New(fakeserver.DefaultGameDef)
+	defer server.Close()
+
+	host := newTestClient(t, server, "host")
+	oppo := newTestClient(t, server, "oppo")
+
+	lobby, err := host.CreateLobby(context.Background(), lockitdownLobby)
+	assert.Nil(t, err)
+	assert.Equal(t, "lockitdown", lobby.GameType)
+	assert.Equal(t, "host", lobby.Host.Name)
+
+	_, err = host.StartGame(context.Background(), lobby.Id.String())
+	assert.NotNil(t, err, "cannot start without an opponent")
 
-	err = bbClient.Authenticate()
-	if err != nil {
-		fmt.Printf("could not authenticate %s", err.Error())
+	joined, err := oppo.JoinLobby(context.Background(), lobby.Id.String())
+	assert.Nil(t, err)
+	assert.Equal(t, []User{lobby.Host, oppo.user}, joined.Players)
+	_, err = oppo.JoinLobby(context.Background(), lobby.Id.String())
+	assert.NotNil(t, err, "cannot join twice")
+
+	game, err := host.StartGame(context.Background(), lobby.Id.String())
+	assert.Nil(t, err)
+	assert.Equal(t, lobby.Id, game.LobbyId)
+	assert.Len(t, game.Players, 2)
+	assert.Equal(t, 1, game.State.PlayerTurn)
+}
+
+func TestMoves(t *testing.T) {
+	server := fakeserver.New(fakeserver.DefaultGameDef)
+	defer server.Close()
+
+	host, oppo, game := startTestGame(t, server)
+
+	resp, err := host.GetPossibleMoves(context.Background(), game.Id.String())
+	assert.Nil(t, err)
+	expected := lockitdown.NewGame(fakeserver.DefaultGameDef).PossibleMoves([]lockitdown.GameMove{})
+	assert.Equal(t, len(expected), len(resp))
+	for _, move := range resp {
+		assert.Equal(t, 1, move.Player)
 	}
 
-	resp, err := bbClient.GetPossibleMoves("3155e207-88c7-437e-bc9c-df820961ed07")
+	_, err = oppo.MakeMove(context.Background(), game.Id.String(), MoveCommand{Json: resp[0]})
+	assert.NotNil(t, err, "not the opponent's turn")
+
+	state, err := host.MakeMove(context.Background(), game.Id.String(), MoveCommand{Json: resp[0]})
+	assert.Nil(t, err)
+	assert.Len(t, state.Robots, 1)
+	assert.Equal(t, 2, state.PlayerTurn)
+
+	fetched, err := oppo.Game(context.Background(), game.Id.String())
+	assert.Nil(t, err)
+	assert.Equal(t, state, fetched.State)
+	assert.Equal(t, 1, fetched.NumMoves)
+}
+
+func TestEngineMove(t *testing.T) {
+	server := fakeserver.New(fakeserver.DefaultGameDef)
+	defer server.Close()
+
+	host, _, game := startTestGame(t, server)
+
+	place := lockitdown.PlaceRobot{
+		Robot:     lockitdown.Pair{Q: 0, R: 5},
+		Direction: lockitdown.NW,
+	}.ToTransport()
+	state, err := host.MakeMove(context.Background(), game.Id.String(), MoveCommand{
+		Json: MoveT{
+			Player: 1,
+			Pos:    internal.Position{Q: place.Position.Q, R: place.Position.R},
+			Action: place.Action,
+		},
+	})
+	assert.Nil(t, err)
 
-	if err != nil {
-		fmt.Println(err.Error())
+	gameState := server.GameState(game.Id)
+	assert.NotNil(t, gameState.RobotAt(lockitdown.Pair{Q: 0, R: 5}))
+	assert.Equal(t, lockitdown.PlayerPosition(1), gameState.PlayerTurn)
+	assert.Equal(t, 1, state.Players[0].PlacedRobots)
+}
+
+func TestListLobbies(t *testing.T) {
+	server := fakeserver.New(fakeserver.DefaultGameDef)
+	defer server.Close()
+
+	host := newTestClient(t, server, "host")
+	oppo := newTestClient(t, server, "oppo")
+
+	lobbies, err := oppo.Lobbies(context.Background())
+	assert.Nil(t, err)
+	assert.Empty(t, lobbies)
+
+	created, err := host.CreateLobby(context.Background(), lockitdownLobby)
+	assert.Nil(t, err)
+
+	lobbies, err = oppo.Lobbies(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, []Lobby{created}, lobbies)
+
+	_, err = oppo.JoinLobby(context.Background(), created.Id.String())
+	assert.Nil(t, err)
+	lobby, err := host.Lobby(context.Background(), created.Id.String())
+	assert.Nil(t, err)
+	assert.Len(t, lobby.Players, 2)
+
+	left, err := oppo.LeaveLobby(context.Background(), created.Id.String())
+	assert.Nil(t, err)
+	assert.Equal(t, []User{created.Host}, left.Players)
+	_, err = oppo.LeaveLobby(context.Background(), created.Id.String())
+	assert.NotNil(t, err, "cannot leave twice")
+
+	// The lobby closes when the host leaves.
+	_, err = host.LeaveLobby(context.Background(), created.Id.String())
+	assert.Nil(t, err)
+	lobbies, err = oppo.Lobbies(context.Background())
+	assert.Nil(t, err)
+	assert.Empty(t, lobbies)
+}
+
+func TestGamesAndHistory(t *testing.T) {
+	server := fakeserver.New(fakeserver.DefaultGameDef)
+	defer server.Close()
+
+	host, oppo, game := startTestGame(t, server)
+
+	active, err := oppo.Games(context.Background(), ActiveGames)
+	assert.Nil(t, err)
+	assert.Len(t, active, 1)
+	assert.Equal(t, game.Id, active[0].Id)
+
+	finished, err := oppo.Games(context.Background(), FinishedGames)
+	assert.Nil(t, err)
+	assert.Empty(t, finished)
+
+	moves, err := host.GetPossibleMoves(context.Background(), game.Id.String())
+	assert.Nil(t, err)
+	_, err = host.MakeMove(context.Background(), game.Id.String(), MoveCommand{Json: moves[3]})
+	assert.Nil(t, err)
+
+	history, err := oppo.MoveHistory(context.Background(), game.Id.String())
+	assert.Nil(t, err)
+	assert.Len(t, history, 1)
+	assert.Equal(t, 0, history[0].Index)
+	assert.Equal(t, 1, history[0].Player.Player)
+	assert.Equal(t, "host", history[0].Player.Username)
+	assert.Equal(t, moves[3].Pos, history[0].Move.Pos)
+	assert.Equal(t, moves[3].Action, history[0].Move.Action)
+}
+
+func TestCreateLobbyOptions(t *testing.T) {
+	server := fakeserver.New(fakeserver.DefaultGameDef)
+	defer server.Close()
+
+	host := newTestClient(t, server, "host")
+	gameDef := lockitdown.GameDef{
+		Board:           lockitdown.Board{HexaBoard: lockitdown.BoardType{ArenaRadius: 3}},
+		Players:         3,
+		MovesPerTurn:    2,
+		RobotsPerPlayer: 4,
+		WinCondition:    "Elimination",
+	}
+	lobby, err := host.CreateLobby(context.Background(), LobbyOptions{
+		GameType: "lockitdown",
+		Players:  3,
+		Options:  gameDef,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, lobby.NumPlayers)
+
+	for _, name := range []string{"oppo", "third"} {
+		_, err = newTestClient(t, server, name).JoinLobby(context.Background(), lobby.Id.String())
+		assert.Nil(t, err)
 	}
+	_, err = newTestClient(t, server, "fourth").JoinLobby(context.Background(), lobby.Id.String())
+	assert.NotNil(t, err, "lobby is full")
 
-	fmt.Printf("%v+\n", resp)
+	game, err := host.StartGame(context.Background(), lobby.Id.String())
+	assert.Nil(t, err)
+	assert.Equal(t, gameDef, game.State.GameDef)
+
+	testcases := []struct {
+		name string
+		opts LobbyOptions
+	}{
+		{"unknown game", LobbyOptions{GameType: "chess"}},
+		{"too many players", LobbyOptions{GameType: "lockitdown", Players: 7}},
+		{"bad options", LobbyOptions{GameType: "lockitdown", Options: map[string]int{"robotsPerPlayer": -1}}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := host.CreateLobby(context.Background(), tc.opts)
+			assert.NotNil(t, err)
+		})
+	}
 }
@@ -0,0 +1,154 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// errors.go classifies failed requests, so callers can tell a flaky
+// connection from an expired login or an illegal move.
+
+type (
+	ErrorKind int
+
+	// Error is returned by every request the client makes. Requests the
+	// server responded to with an error status wrap an *APIError.
+	Error struct {
+		Kind ErrorKind
+		Err  error
+		// retryAfter is the delay the server asked for, if any.
+		retryAfter time.Duration
+	}
+
+	// APIError is a response from the server with a non 200 status.
+	//
+	//	var apiErr *client.APIError
+	//	if errors.As(err, &apiErr) && apiErr.IsIllegalMove() { ... }
+	APIError struct {
+		StatusCode int
+		Method     string
+		// Endpoint is the path of the request, including its query.
+		Endpoint string
+		// Payload is the decoded error response. Non-JSON responses are kept
+		// as the payload's Message.
+		Payload ErrorPayload
+	}
+
+	// ErrorPayload is the body of an error response from the server.
+	ErrorPayload struct {
+		Error   string `json:"error,omitempty"`
+		Message string `json:"message,omitempty"`
+	}
+)
+
+const (
+	// The request could not be completed, e.g. the connection failed or
+	// timed out.
+	NetworkError ErrorKind = iota
+	// The server is overloaded or temporarily down.
+	UnavailableError
+	// The user is not logged in, or not allowed to make the request.
+	AuthError
+	// The server understood the request and refused it, e.g. an illegal move.
+	RejectedError
+	// The server failed to handle the request.
+	ServerError
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case NetworkError:
+		return "network error"
+	case UnavailableError:
+		return "server unavailable"
+	case AuthError:
+		return "auth error"
+	case RejectedError:
+		return "request rejected"
+	case ServerError:
+		return "server error"
+	}
+	return fmt.Sprintf("ErrorKind(%d)", int(k))
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Kind, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Temporary reports whether the request may succeed if retried.
+func (e *Error) Temporary() bool {
+	return e.Kind == NetworkError || e.Kind == UnavailableError
+}
+
+func newAPIError(method, endpoint string, status int, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: status,
+		Method:     method,
+		Endpoint:   endpoint,
+	}
+	if err := json.Unmarshal(body, &apiErr.Payload); err != nil {
+		apiErr.Payload = ErrorPayload{Message: strings.TrimSpace(string(body))}
+	}
+	return apiErr
+}
+
+func (e *APIError) Error() string {
+	message := e.Payload.Message
+	if e.Payload.Error != "" {
+		message = strings.TrimSpace(e.Payload.Error + " " + message)
+	}
+	return fmt.Sprintf("%s %s: status %d: %s", e.Method, e.Endpoint, e.StatusCode, message)
+}
+
+// IsAuthExpired reports whether the request failed because the user isn't
+// logged in, and should authenticate again.
+func (e *APIError) IsAuthExpired() bool {
+	return e.StatusCode == http.StatusUnauthorized
+}
+
+// IsIllegalMove reports whether the server refused a move as against the
+// rules of the game.
+func (e *APIError) IsIllegalMove() bool {
+	return kindOfStatus(e.StatusCode) == RejectedError &&
+		strings.HasPrefix(e.Endpoint, "/api/game/") && strings.HasSuffix(e.Endpoint, "/move")
+}
+
+func kindOfStatus(status int) ErrorKind {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return AuthError
+	case status == http.StatusTooManyRequests ||
+		status == http.StatusBadGateway ||
+		status == http.StatusServiceUnavailable ||
+		status == http.StatusGatewayTimeout:
+		return UnavailableError
+	case status >= 400 && status < 500:
+		return RejectedError
+	}
+	return ServerError
+}
+
+// parseRetryAfter reads a Retry-After header, either in seconds or as an
+// HTTP date. Returns 0 if the header is missing or malformed.
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		if wait := time.Until(date); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
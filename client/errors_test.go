@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rwsargent/boardbots-go/client/fakeserver"
+	"github.com/rwsargent/boardbots-go/lockitdown"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIllegalMoveAPIError(t *testing.T) {
+	server := fakeserver.New(fakeserver.DefaultGameDef)
+	defer server.Close()
+
+	host, _, game := startTestGame(t, server)
+	_, err := host.MakeMove(context.Background(), game.Id.String(), MoveCommand{
+		Json: MoveT{Player: 1, Action: "Advance"},
+	})
+
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.True(t, apiErr.IsIllegalMove())
+	assert.False(t, apiErr.IsAuthExpired())
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	assert.Equal(t, "POST", apiErr.Method)
+	assert.Equal(t, "/api/game/"+game.Id.String()+"/move", apiErr.Endpoint)
+	assert.Equal(t, "no robot at location {0, 0}", apiErr.Payload.Message)
+}
+
+func TestAuthExpiredAPIError(t *testing.T) {
+	server := fakeserver.New(fakeserver.DefaultGameDef)
+	defer server.Close()
+
+	_, _, game := startTestGame(t, server)
+	stranger, err := NewBoardBotClient[lockitdown.TransportState](Credentials{Username: "stranger"}, server.URL)
+	assert.Nil(t, err)
+
+	testcases := []struct {
+		name string
+		call func() error
+	}{
+		{"get", func() error {
+			_, err := stranger.Game(context.Background(), game.Id.String())
+			return err
+		}},
+		{"post", func() error {
+			_, err := stranger.CreateLobby(context.Background(), lockitdownLobby)
+			return err
+		}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var apiErr *APIError
+			assert.True(t, errors.As(tc.call(), &apiErr))
+			assert.True(t, apiErr.IsAuthExpired())
+			assert.False(t, apiErr.IsIllegalMove())
+		})
+	}
+}
+
+func TestPlainTextAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		http.Error(w, "nope", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	_, err := Get[User](context.Background(), testClient(t, server.URL), "/user?x=1")
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, ErrorPayload{Message: "nope"}, apiErr.Payload)
+	assert.Equal(t, "/user?x=1", apiErr.Endpoint)
+	assert.EqualError(t, apiErr, "GET /user?x=1: status 404: nope")
+}
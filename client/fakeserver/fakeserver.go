@@ -0,0 +1,809 @@
+// Package fakeserver is an in-process stand-in for the boardbots.dev server.
+// It implements the endpoints used by the client package on top of
+// httptest, backed by the lockitdown engine, so the client and bots can be
+// exercised without a network.
+//
+//	server := fakeserver.New(fakeserver.DefaultGameDef)
+//	defer server.Close()
+//	bbClient, _ := client.NewBoardBotClient[lockitdown.TransportState](creds, server.URL)
+//	gameId, _ := server.StartGame("host", "oppo")
+package fakeserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rwsargent/boardbots-go/lockitdown"
+)
+
+type (
+	// Server is a running fake boardbots server. All state is kept in memory.
+	Server struct {
+		*httptest.Server
+
+		// GameDef is used for lobbies created without options.
+		GameDef lockitdown.GameDef
+		// LongPollTimeout is how long a request for a game waits for a new
+		// move when it is made with ?since={numMoves}.
+		LongPollTimeout time.Duration
+		// DisableEvents makes the event stream endpoint respond 404, like a
+		// server that only supports polling.
+		DisableEvents bool
+		// MaxStreamEvents ends event streams after that many events, like a
+		// server going away mid game. Streams aren't cut short if it's 0.
+		MaxStreamEvents int
+
+		done     chan struct{}
+		lock     sync.Mutex
+		users    map[string]*user
+		sessions map[string]*user
+		lobbies  map[uuid.UUID]*lobby
+		games    map[uuid.UUID]*game
+	}
+
+	user struct {
+		Id   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	lobby struct {
+		Id         uuid.UUID          `json:"id"`
+		Host       user               `json:"host"`
+		GameType   string             `json:"gameType"`
+		NumPlayers int                `json:"numPlayers"`
+		Options    lockitdown.GameDef `json:"options"`
+		Status     string             `json:"status"`
+		Players    []user             `json:"players"`
+		CreatedAt  int64              `json:"createdAt"`
+	}
+
+	game struct {
+		Id        uuid.UUID                 `json:"id"`
+		LobbyId   uuid.UUID                 `json:"lobbyId"`
+		Players   []user                    `json:"players"`
+		GameType  string                    `json:"gameType"`
+		State     lockitdown.TransportState `json:"state"`
+		Status    string                    `json:"status"`
+		NumMoves  int                       `json:"numMoves"`
+		StartedAt int64                     `json:"startedAt"`
+
+		// changed is closed, and replaced, whenever the state changes.
+		changed chan struct{}
+		moves   []moveRecord
+	}
+
+	player struct {
+		Player   int    `json:"player"`
+		Username string `json:"username"`
+		UserId   int    `json:"userId"`
+	}
+
+	moveRecord struct {
+		Index     int    `json:"index"`
+		Player    player `json:"player"`
+		Move      moveT  `json:"move"`
+		CreatedAt int64  `json:"createdAt"`
+	}
+
+	createLobbyReq struct {
+		GameType   string          `json:"gameType"`
+		NumPlayers int             `json:"numPlayers"`
+		Options    json.RawMessage `json:"options"`
+	}
+
+	moveT struct {
+		Player int             `json:"player"`
+		Pos    lockitdown.Pair `json:"pos"`
+		Action json.RawMessage `json:"action"`
+	}
+
+	moveCommand struct {
+		Json moveT `json:"json"`
+	}
+
+	potentialMove struct {
+		Player int             `json:"player"`
+		Pos    lockitdown.Pair `json:"pos"`
+		Action any             `json:"action"`
+	}
+
+	errorPayload struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	}
+
+	// httpError is returned by handlers to set the response status.
+	httpError struct {
+		status  int
+		message string
+	}
+)
+
+const (
+	sessionCookie = "session"
+
+	LobbyOpen    = "Open"
+	LobbyStarted = "Started"
+	LobbyClosed  = "Closed"
+
+	GameInProgress = "InProgress"
+	GameFinished   = "Finished"
+)
+
+// DefaultGameDef is the two player LockItDown game played on boardbots.dev.
+var DefaultGameDef = lockitdown.DefaultGameDef
+
+// New starts a fake server. Games started on it use gameDef. The caller
+// should call Close when finished, to shut it down.
+func New(gameDef lockitdown.GameDef) *Server {
+	s := &Server{
+		GameDef:         gameDef,
+		LongPollTimeout: 30 * time.Second,
+		done:            make(chan struct{}),
+		users:           make(map[string]*user),
+		sessions:        make(map[string]*user),
+		lobbies:         make(map[uuid.UUID]*lobby),
+		games:           make(map[uuid.UUID]*game),
+	}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// Close ends open event streams and long polls, and shuts down the server.
+func (s *Server) Close() {
+	close(s.done)
+	s.Server.Close()
+}
+
+// ServeHTTP routes requests to the fake endpoints.
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	path := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	api := path[0] == "api"
+
+	// Streams and long polls wait for changes without holding the lock.
+	if api && len(path) == 4 && path[1] == "game" && path[3] == "events" && !s.DisableEvents {
+		s.streamGame(w, req, path[2])
+		return
+	}
+	if api && len(path) == 3 && path[1] == "game" && req.URL.Query().Has("since") {
+		s.waitForChange(req, path[2])
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var resp any
+	var err error
+	switch {
+	case req.URL.Path == "/auth/login":
+		err = s.login(w, req)
+	case req.URL.Path == "/user":
+		resp, err = s.currentUser(req)
+	case api && len(path) == 2 && path[1] == "lobby":
+		resp, err = s.openLobbies(req)
+	case api && len(path) == 3 && path[1] == "lobby" && path[2] == "create":
+		resp, err = s.createLobby(req)
+	case api && len(path) == 3 && path[1] == "lobby":
+		resp, err = s.lobby(req, path[2])
+	case api && len(path) == 4 && path[1] == "lobby" && path[3] == "leave":
+		resp, err = s.leaveLobby(req, path[2])
+	case api && len(path) == 4 && path[1] == "lobby" && path[3] == "join":
+		resp, err = s.joinLobby(req, path[2])
+	case api && len(path) == 4 && path[1] == "lobby" && path[3] == "start":
+		resp, err = s.startGame(req, path[2])
+	case api && len(path) == 3 && path[1] == "game":
+		resp, err = s.game(req, path[2])
+	case api && len(path) == 4 && path[1] == "game" && path[3] == "move":
+		resp, err = s.move(req, path[2])
+	case api && len(path) == 4 && path[1] == "game" && path[3] == "potential-moves":
+		resp, err = s.potentialMoves(req, path[2])
+	case api && len(path) == 4 && path[1] == "game" && path[3] == "moves":
+		resp, err = s.moveHistory(req, path[2])
+	case api && len(path) == 3 && path[1] == "user" && path[2] == "games":
+		resp, err = s.userGames(req)
+	default:
+		err = httpError{http.StatusNotFound, "not found"}
+	}
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if resp == nil {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// GameState returns the current state of a started game, or nil if there
+// is no such game.
+func (s *Server) GameState(gameId uuid.UUID) *lockitdown.GameState {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	g, found := s.games[gameId]
+	if !found {
+		return nil
+	}
+	return lockitdown.StateFromTransport(&g.State)
+}
+
+// SetGameState replaces the state of a started game, finishing the game if
+// the state has a winner.
+func (s *Server) SetGameState(gameId uuid.UUID, state *lockitdown.GameState) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	g, found := s.games[gameId]
+	if !found {
+		return fmt.Errorf("no game %s", gameId)
+	}
+	if state.Winner >= 0 {
+		g.Status = GameFinished
+	}
+	return g.setState(state)
+}
+
+// FinishGame ends a started game without changing its state, as if it had
+// been abandoned.
+func (s *Server) FinishGame(gameId uuid.UUID) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	g, found := s.games[gameId]
+	if !found {
+		return fmt.Errorf("no game %s", gameId)
+	}
+	g.Status = GameFinished
+	if g.changed != nil {
+		close(g.changed)
+	}
+	g.changed = make(chan struct{})
+	return nil
+}
+
+// StartGame starts a game between the users, the first hosting it, as if
+// they had created, joined and started a lobby. Users who haven't logged
+// in yet are created. The game is played with GameDef, for as many players
+// as there are users.
+func (s *Server) StartGame(usernames ...string) (uuid.UUID, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	gameDef := s.GameDef
+	gameDef.Players = len(usernames)
+	if err := gameDef.Validate(); err != nil {
+		return uuid.Nil, err
+	}
+	l := &lobby{
+		Id:         uuid.New(),
+		GameType:   "lockitdown",
+		NumPlayers: gameDef.Players,
+		Options:    gameDef,
+		Status:     LobbyOpen,
+		CreatedAt:  time.Now().UnixMilli(),
+	}
+	for _, name := range usernames {
+		l.Players = append(l.Players, *s.userNamed(name))
+	}
+	l.Host = l.Players[0]
+	s.lobbies[l.Id] = l
+	g, err := s.start(l)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return g.Id, nil
+}
+
+func (s *Server) login(w http.ResponseWriter, req *http.Request) error {
+	if err := method(req, http.MethodGet); err != nil {
+		return err
+	}
+	name := req.URL.Query().Get("name")
+	if name == "" {
+		return httpError{http.StatusBadRequest, "missing name"}
+	}
+	session := uuid.NewString()
+	s.sessions[session] = s.userNamed(name)
+	http.SetCookie(w, &http.Cookie{Name: sessionCookie, Value: session, Path: "/"})
+	return nil
+}
+
+func (s *Server) currentUser(req *http.Request) (any, error) {
+	if err := method(req, http.MethodGet); err != nil {
+		return nil, err
+	}
+	return s.authenticated(req)
+}
+
+func (s *Server) createLobby(req *http.Request) (any, error) {
+	u, err := s.authenticatedPost(req)
+	if err != nil {
+		return nil, err
+	}
+	var lobbyReq createLobbyReq
+	if err := json.NewDecoder(req.Body).Decode(&lobbyReq); err != nil {
+		return nil, httpError{http.StatusBadRequest, err.Error()}
+	}
+	if lobbyReq.GameType != "lockitdown" {
+		return nil, httpError{http.StatusBadRequest, fmt.Sprintf("unsupported game type %q", lobbyReq.GameType)}
+	}
+	// Options only override the fields they set.
+	gameDef := s.GameDef
+	if len(lobbyReq.Options) > 0 {
+		if err := json.Unmarshal(lobbyReq.Options, &gameDef); err != nil {
+			return nil, httpError{http.StatusBadRequest, err.Error()}
+		}
+	}
+	if lobbyReq.NumPlayers > 0 {
+		gameDef.Players = lobbyReq.NumPlayers
+	}
+	if err := gameDef.Validate(); err != nil {
+		return nil, httpError{http.StatusBadRequest, err.Error()}
+	}
+
+	l := &lobby{
+		Id:         uuid.New(),
+		Host:       *u,
+		GameType:   lobbyReq.GameType,
+		NumPlayers: gameDef.Players,
+		Options:    gameDef,
+		Status:     LobbyOpen,
+		Players:    []user{*u},
+		CreatedAt:  time.Now().UnixMilli(),
+	}
+	s.lobbies[l.Id] = l
+	return l, nil
+}
+
+func (s *Server) joinLobby(req *http.Request, lobbyId string) (any, error) {
+	u, err := s.authenticatedPost(req)
+	if err != nil {
+		return nil, err
+	}
+	l, err := s.findLobby(lobbyId)
+	if err != nil {
+		return nil, err
+	}
+	if l.Status != LobbyOpen {
+		return nil, httpError{http.StatusBadRequest, "lobby is not open"}
+	}
+	for _, player := range l.Players {
+		if player.Id == u.Id {
+			return nil, httpError{http.StatusBadRequest, "already in lobby"}
+		}
+	}
+	if len(l.Players) >= l.NumPlayers {
+		return nil, httpError{http.StatusBadRequest, "lobby is full"}
+	}
+	l.Players = append(l.Players, *u)
+	return l, nil
+}
+
+func (s *Server) openLobbies(req *http.Request) (any, error) {
+	if err := method(req, http.MethodGet); err != nil {
+		return nil, err
+	}
+	if _, err := s.authenticated(req); err != nil {
+		return nil, err
+	}
+	open := make([]*lobby, 0)
+	for _, l := range s.lobbies {
+		if l.Status == LobbyOpen {
+			open = append(open, l)
+		}
+	}
+	sort.Slice(open, func(i, j int) bool {
+		return open[i].CreatedAt < open[j].CreatedAt
+	})
+	return open, nil
+}
+
+func (s *Server) lobby(req *http.Request, lobbyId string) (any, error) {
+	if err := method(req, http.MethodGet); err != nil {
+		return nil, err
+	}
+	if _, err := s.authenticated(req); err != nil {
+		return nil, err
+	}
+	return s.findLobby(lobbyId)
+}
+
+// leaveLobby removes a player from an open lobby. The lobby is closed if
+// the host leaves.
+func (s *Server) leaveLobby(req *http.Request, lobbyId string) (any, error) {
+	u, err := s.authenticatedPost(req)
+	if err != nil {
+		return nil, err
+	}
+	l, err := s.findLobby(lobbyId)
+	if err != nil {
+		return nil, err
+	}
+	if l.Status != LobbyOpen {
+		return nil, httpError{http.StatusBadRequest, "lobby is not open"}
+	}
+	for i, player := range l.Players {
+		if player.Id == u.Id {
+			l.Players = append(l.Players[:i], l.Players[i+1:]...)
+			if l.Host.Id == u.Id {
+				l.Status = LobbyClosed
+			}
+			return l, nil
+		}
+	}
+	return nil, httpError{http.StatusBadRequest, "not in lobby"}
+}
+
+func (s *Server) startGame(req *http.Request, lobbyId string) (any, error) {
+	u, err := s.authenticatedPost(req)
+	if err != nil {
+		return nil, err
+	}
+	l, err := s.findLobby(lobbyId)
+	if err != nil {
+		return nil, err
+	}
+	if l.Host.Id != u.Id {
+		return nil, httpError{http.StatusForbidden, "only the host can start the game"}
+	}
+	if l.Status != LobbyOpen {
+		return nil, httpError{http.StatusBadRequest, "game already started"}
+	}
+	if len(l.Players) != l.NumPlayers {
+		return nil, httpError{http.StatusBadRequest, fmt.Sprintf("need %d players, have %d", l.NumPlayers, len(l.Players))}
+	}
+
+	return s.start(l)
+}
+
+// start starts the game of a full lobby.
+func (s *Server) start(l *lobby) (*game, error) {
+	l.Status = LobbyStarted
+	g := &game{
+		Id:        uuid.New(),
+		LobbyId:   l.Id,
+		Players:   l.Players,
+		GameType:  l.GameType,
+		Status:    GameInProgress,
+		StartedAt: time.Now().UnixMilli(),
+	}
+	if err := g.setState(lockitdown.NewGame(l.Options)); err != nil {
+		return nil, err
+	}
+	s.games[g.Id] = g
+	return g, nil
+}
+
+func (s *Server) game(req *http.Request, gameId string) (any, error) {
+	if err := method(req, http.MethodGet); err != nil {
+		return nil, err
+	}
+	if _, err := s.authenticated(req); err != nil {
+		return nil, err
+	}
+	return s.findGame(gameId)
+}
+
+// streamGame writes the game as a server-sent event, and again every time
+// it changes, until the game is finished.
+func (s *Server) streamGame(w http.ResponseWriter, req *http.Request, gameId string) {
+	s.lock.Lock()
+	g, err := s.findGame(gameId)
+	if _, authErr := s.authenticated(req); authErr != nil {
+		err = authErr
+	}
+	s.lock.Unlock()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, errors.New("streaming unsupported"))
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	for events := 1; ; events++ {
+		s.lock.Lock()
+		b, err := json.Marshal(g)
+		changed := g.changed
+		finished := g.Status != GameInProgress
+		s.lock.Unlock()
+		if err != nil {
+			return
+		}
+
+		fmt.Fprintf(w, "data: %s\n\n", b)
+		flusher.Flush()
+		if finished || events == s.MaxStreamEvents {
+			return
+		}
+
+		select {
+		case <-changed:
+		case <-req.Context().Done():
+			return
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// waitForChange blocks a long poll until a move is made after the since
+// query parameter, or LongPollTimeout passes.
+func (s *Server) waitForChange(req *http.Request, gameId string) {
+	since, err := strconv.Atoi(req.URL.Query().Get("since"))
+	if err != nil {
+		return
+	}
+	s.lock.Lock()
+	g, err := s.findGame(gameId)
+	if err != nil || g.NumMoves != since || g.Status != GameInProgress {
+		s.lock.Unlock()
+		return
+	}
+	changed := g.changed
+	s.lock.Unlock()
+
+	timer := time.NewTimer(s.LongPollTimeout)
+	defer timer.Stop()
+	select {
+	case <-changed:
+	case <-timer.C:
+	case <-req.Context().Done():
+	case <-s.done:
+	}
+}
+
+func (s *Server) move(req *http.Request, gameId string) (any, error) {
+	u, err := s.authenticatedPost(req)
+	if err != nil {
+		return nil, err
+	}
+	g, err := s.findGame(gameId)
+	if err != nil {
+		return nil, err
+	}
+	if g.Status != GameInProgress {
+		return nil, httpError{http.StatusBadRequest, "game is over"}
+	}
+
+	var command moveCommand
+	if err := json.NewDecoder(req.Body).Decode(&command); err != nil {
+		return nil, httpError{http.StatusBadRequest, err.Error()}
+	}
+	seat := g.seat(u)
+	if seat < 0 {
+		return nil, httpError{http.StatusForbidden, "not a player in this game"}
+	}
+	if command.Json.Player != seat+1 {
+		return nil, httpError{http.StatusForbidden, fmt.Sprintf("cannot move for player %d", command.Json.Player)}
+	}
+
+	move, err := lockitdown.MoveFromTransport(lockitdown.BoardbotsMove{
+		Position: command.Json.Pos,
+		Action:   command.Json.Action,
+	}, lockitdown.PlayerPosition(seat))
+	if err != nil {
+		return nil, httpError{http.StatusBadRequest, err.Error()}
+	}
+
+	state := lockitdown.StateFromTransport(&g.State)
+	err = state.Move(&move)
+	if err != nil && state.Winner < 0 {
+		return nil, httpError{http.StatusBadRequest, err.Error()}
+	}
+	if state.Winner >= 0 {
+		g.Status = GameFinished
+	}
+	if err := g.setState(state); err != nil {
+		return nil, err
+	}
+	g.moves = append(g.moves, moveRecord{
+		Index: g.NumMoves,
+		Player: player{
+			Player:   seat + 1,
+			Username: u.Name,
+			UserId:   u.Id,
+		},
+		Move:      command.Json,
+		CreatedAt: time.Now().UnixMilli(),
+	})
+	g.NumMoves++
+	return g.State, nil
+}
+
+func (s *Server) moveHistory(req *http.Request, gameId string) (any, error) {
+	if err := method(req, http.MethodGet); err != nil {
+		return nil, err
+	}
+	if _, err := s.authenticated(req); err != nil {
+		return nil, err
+	}
+	g, err := s.findGame(gameId)
+	if err != nil {
+		return nil, err
+	}
+	return append([]moveRecord{}, g.moves...), nil
+}
+
+// userGames lists the user's games, filtered by the status query parameter
+// of "active" or "finished".
+func (s *Server) userGames(req *http.Request) (any, error) {
+	if err := method(req, http.MethodGet); err != nil {
+		return nil, err
+	}
+	u, err := s.authenticated(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var status string
+	switch filter := req.URL.Query().Get("status"); filter {
+	case "active":
+		status = GameInProgress
+	case "finished":
+		status = GameFinished
+	case "":
+	default:
+		return nil, httpError{http.StatusBadRequest, fmt.Sprintf("unknown status %q", filter)}
+	}
+
+	games := make([]*game, 0)
+	for _, g := range s.games {
+		if g.seat(u) >= 0 && (status == "" || g.Status == status) {
+			games = append(games, g)
+		}
+	}
+	sort.Slice(games, func(i, j int) bool {
+		return games[i].StartedAt < games[j].StartedAt
+	})
+	return games, nil
+}
+
+func (s *Server) potentialMoves(req *http.Request, gameId string) (any, error) {
+	if err := method(req, http.MethodGet); err != nil {
+		return nil, err
+	}
+	if _, err := s.authenticated(req); err != nil {
+		return nil, err
+	}
+	g, err := s.findGame(gameId)
+	if err != nil {
+		return nil, err
+	}
+
+	moves := make([]potentialMove, 0)
+	state := lockitdown.StateFromTransport(&g.State)
+	for it := lockitdown.NewMoveIterator(state); it.Next(); {
+		move := it.Get()
+		transport := move.ToTransport()
+		moves = append(moves, potentialMove{
+			Player: int(move.Player) + 1,
+			Pos:    transport.Position,
+			Action: transport.Action,
+		})
+	}
+	return moves, nil
+}
+
+// userNamed returns the user with the name, creating it on first use.
+func (s *Server) userNamed(name string) *user {
+	u, found := s.users[name]
+	if !found {
+		u = &user{Id: len(s.users) + 1, Name: name}
+		s.users[name] = u
+	}
+	return u
+}
+
+func (s *Server) authenticated(req *http.Request) (*user, error) {
+	cookie, err := req.Cookie(sessionCookie)
+	if err != nil {
+		return nil, httpError{http.StatusUnauthorized, "not logged in"}
+	}
+	u, found := s.sessions[cookie.Value]
+	if !found {
+		return nil, httpError{http.StatusUnauthorized, "unknown session"}
+	}
+	return u, nil
+}
+
+func (s *Server) authenticatedPost(req *http.Request) (*user, error) {
+	if err := method(req, http.MethodPost); err != nil {
+		return nil, err
+	}
+	return s.authenticated(req)
+}
+
+func (s *Server) findLobby(lobbyId string) (*lobby, error) {
+	id, err := uuid.Parse(lobbyId)
+	if err != nil {
+		return nil, httpError{http.StatusBadRequest, err.Error()}
+	}
+	l, found := s.lobbies[id]
+	if !found {
+		return nil, httpError{http.StatusNotFound, "no such lobby"}
+	}
+	return l, nil
+}
+
+func (s *Server) findGame(gameId string) (*game, error) {
+	id, err := uuid.Parse(gameId)
+	if err != nil {
+		return nil, httpError{http.StatusBadRequest, err.Error()}
+	}
+	g, found := s.games[id]
+	if !found {
+		return nil, httpError{http.StatusNotFound, "no such game"}
+	}
+	return g, nil
+}
+
+// seat returns the 0 based position of the user in the game, or -1.
+func (g *game) seat(u *user) int {
+	for i, player := range g.Players {
+		if player.Id == u.Id {
+			return i
+		}
+	}
+	return -1
+}
+
+// setState stores the state in its wire format, the way a real server would
+// persist it between requests.
+func (g *game) setState(state *lockitdown.GameState) error {
+	b, err := json.Marshal(lockitdown.ConvertToTransport(state))
+	if err != nil {
+		return err
+	}
+	g.State = lockitdown.TransportState{}
+	if err := json.Unmarshal(b, &g.State); err != nil {
+		return err
+	}
+	if g.changed != nil {
+		close(g.changed)
+	}
+	g.changed = make(chan struct{})
+	return nil
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	var httpErr httpError
+	if !errors.As(err, &httpErr) {
+		httpErr = httpError{http.StatusInternalServerError, err.Error()}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpErr.status)
+	json.NewEncoder(w).Encode(errorPayload{
+		Error:   http.StatusText(httpErr.status),
+		Message: httpErr.message,
+	})
+}
+
+func method(req *http.Request, method string) error {
+	if req.Method != method {
+		return httpError{http.StatusMethodNotAllowed, fmt.Sprintf("expected %s", method)}
+	}
+	return nil
+}
+
+func (e httpError) Error() string {
+	return fmt.Sprintf("%d: %s", e.status, e.message)
+}
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
@@ -32,11 +33,17 @@ type (
 		Json any `json:"json"`
 	}
 
+	// MoveResp is a move made in a game, as recorded by the server.
 	MoveResp struct {
-		Index  int    `json:"index"`
-		Player Player `json:"player"`
+		Index     int         `json:"index"`
+		Player    Player      `json:"player"`
+		Move      MoveT       `json:"move"`
+		CreatedAt json.Number `json:"createdAt"`
 	}
 
+	// GameStatusFilter selects which of the user's games to list.
+	GameStatusFilter string
+
 	MoveT struct {
 		Player int               `json:"player"`
 		Pos    internal.Position `json:"pos"`
@@ -44,14 +51,37 @@ type (
 	}
 )
 
-func (c *BoardBotClient[S]) Game(gameId string) (Game[S], error) {
-	return Get[Game[S]](c, fmt.Sprintf("/api/game/%s", gameId))
+const (
+	ActiveGames   GameStatusFilter = "active"
+	FinishedGames GameStatusFilter = "finished"
+
+	// GameFinished is the Status of games that have a winner.
+	GameFinished = "Finished"
+)
+
+// Finished is true once the game has a winner.
+func (g Game[S]) Finished() bool {
+	return g.Status == GameFinished
+}
+
+func (c *BoardBotClient[S]) Game(ctx context.Context, gameId string) (Game[S], error) {
+	return Get[Game[S]](ctx, c, fmt.Sprintf("/api/game/%s", gameId))
+}
+
+func (c *BoardBotClient[S]) MakeMove(ctx context.Context, gameId string, move MoveCommand) (S, error) {
+	return Post[MoveCommand, S](ctx, c, fmt.Sprintf("/api/game/%s/move", gameId), move)
+}
+
+func (c *BoardBotClient[S]) GetPossibleMoves(ctx context.Context, gameId string) ([]MoveT, error) {
+	return Get[[]MoveT](ctx, c, fmt.Sprintf("/api/game/%s/potential-moves", gameId))
 }
 
-func (c *BoardBotClient[S]) MakeMove(gameId string, move MoveCommand) (S, error) {
-	return Post[MoveCommand, S](c, fmt.Sprintf("/api/game/%s/move", gameId), move)
+// Games lists the authenticated user's games with the given status.
+func (c *BoardBotClient[S]) Games(ctx context.Context, status GameStatusFilter) ([]Game[S], error) {
+	return Get[[]Game[S]](ctx, c, fmt.Sprintf("/api/user/games?status=%s", status))
 }
 
-func (c *BoardBotClient[S]) GetPossibleMoves(gameId string) ([]MoveT, error) {
-	return Get[[]MoveT](c, fmt.Sprintf("/api/game/%s/potential-moves", gameId))
+// MoveHistory lists every move made in the game, in order.
+func (c *BoardBotClient[S]) MoveHistory(ctx context.Context, gameId string) ([]MoveResp, error) {
+	return Get[[]MoveResp](ctx, c, fmt.Sprintf("/api/game/%s/moves", gameId))
 }
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
@@ -12,47 +13,79 @@ import (
 type (
 	CreateLobbyReq struct {
 		GameType       string `json:"gameType"`
+		NumPlayers     int    `json:"numPlayers"`
 		InitialPlayers []int  `json:"initialPlayers"`
+		Options        any    `json:"options,omitempty"`
 	}
 
-	CreateLobbyResponse struct {
-		Id        uuid.UUID   `json:"id"`
-		Host      User        `json:"host"`
-		GameType  string      `json:"gameType"`
-		Status    string      `json:"status"`
-		Players   []User      `json:"players"`
-		CreatedAt json.Number `json:"createdAt"`
+	// LobbyOptions describes the game a lobby is created for.
+	LobbyOptions struct {
+		GameType string
+		// Players is the number of players the game seats. Zero leaves it
+		// up to the server.
+		Players int
+		// Options are specific to the game type, e.g. a lockitdown.GameDef.
+		// Nil options use the server's defaults.
+		Options any
 	}
 
-	JoinLobbyReq struct {
+	// Lobby gathers players before a game starts.
+	Lobby struct {
+		Id         uuid.UUID       `json:"id"`
+		Host       User            `json:"host"`
+		GameType   string          `json:"gameType"`
+		NumPlayers int             `json:"numPlayers"`
+		Options    json.RawMessage `json:"options,omitempty"`
+		Status     string          `json:"status"`
+		Players    []User          `json:"players"`
+		CreatedAt  json.Number     `json:"createdAt"`
 	}
 
-	JoinLobbyResponse struct {
+	CreateLobbyResponse = Lobby
+
+	JoinLobbyReq struct {
 	}
 
-	Empty struct{}
+	JoinLobbyResponse = Lobby
 
-	StartGameResp struct {
-	}
+	LeaveLobbyResponse = Lobby
+
+	Empty struct{}
 )
 
-func (c *BoardBotClient[S]) CreateLobby() (CreateLobbyResponse, error) {
+// CreateLobby creates a lobby for the game described by opts, hosted by
+// the authenticated user.
+func (c *BoardBotClient[S]) CreateLobby(ctx context.Context, opts LobbyOptions) (CreateLobbyResponse, error) {
 	lobbyReq := CreateLobbyReq{
-		GameType:       "lockitdown",
+		GameType:       opts.GameType,
+		NumPlayers:     opts.Players,
 		InitialPlayers: []int{},
+		Options:        opts.Options,
 	}
 
-	return Post[CreateLobbyReq, CreateLobbyResponse](c, "/api/lobby/create", lobbyReq)
+	return Post[CreateLobbyReq, CreateLobbyResponse](ctx, c, "/api/lobby/create", lobbyReq)
+}
+
+// Lobbies lists the lobbies that are open to join.
+func (c *BoardBotClient[S]) Lobbies(ctx context.Context) ([]Lobby, error) {
+	return Get[[]Lobby](ctx, c, "/api/lobby")
 }
 
-func (c *BoardBotClient[S]) JoinLobby(lobbyId string) error {
+func (c *BoardBotClient[S]) Lobby(ctx context.Context, lobbyId string) (Lobby, error) {
+	return Get[Lobby](ctx, c, fmt.Sprintf("/api/lobby/%s", lobbyId))
+}
+
+func (c *BoardBotClient[S]) JoinLobby(ctx context.Context, lobbyId string) (JoinLobbyResponse, error) {
 	req := JoinLobbyReq{}
 
-	_, err := Post[JoinLobbyReq, string](c, fmt.Sprintf("/api/lobby/%s/join", lobbyId), req)
+	return Post[JoinLobbyReq, JoinLobbyResponse](ctx, c, fmt.Sprintf("/api/lobby/%s/join", lobbyId), req)
+}
 
-	return err
+// LeaveLobby removes the user from a lobby that hasn't started.
+func (c *BoardBotClient[S]) LeaveLobby(ctx context.Context, lobbyId string) (LeaveLobbyResponse, error) {
+	return Post[Empty, LeaveLobbyResponse](ctx, c, fmt.Sprintf("/api/lobby/%s/leave", lobbyId), Empty{})
 }
 
-func (c *BoardBotClient[S]) StartGame(lobbyId string) (Game[S], error) {
-	return Post[Empty, Game[S]](c, fmt.Sprintf("/api/lobby/%s/start", lobbyId), Empty{})
+func (c *BoardBotClient[S]) StartGame(ctx context.Context, lobbyId string) (Game[S], error) {
+	return Post[Empty, Game[S]](ctx, c, fmt.Sprintf("/api/lobby/%s/start", lobbyId), Empty{})
 }
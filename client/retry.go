@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+type (
+	// RetryPolicy retries temporary failures with exponential backoff and
+	// full jitter. A Retry-After from the server takes precedence over the
+	// backoff, but is waited at most MaxDelay.
+	RetryPolicy struct {
+		// Attempts is the maximum number of attempts, including the first.
+		Attempts int
+		// BaseDelay is the upper bound of the first backoff, doubling with
+		// every attempt up to MaxDelay.
+		BaseDelay time.Duration
+		MaxDelay  time.Duration
+	}
+)
+
+var DefaultRetryPolicy = RetryPolicy{
+	Attempts:  5,
+	BaseDelay: 200 * time.Millisecond,
+	MaxDelay:  10 * time.Second,
+}
+
+// retry calls attempt until it succeeds, fails permanently, the attempts
+// are used up, or the context is done.
+func (p RetryPolicy) retry(ctx context.Context, attempt func() error) error {
+	var err error
+	for i := 0; ; i++ {
+		err = attempt()
+		var clientErr *Error
+		if err == nil || !errors.As(err, &clientErr) || !clientErr.Temporary() ||
+			i+1 >= p.Attempts || ctx.Err() != nil {
+			return err
+		}
+
+		timer := time.NewTimer(p.delay(i, clientErr.retryAfter))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+	}
+}
+
+// delay is how long to wait before the attempt following attempt i.
+func (p RetryPolicy) delay(i int, retryAfter time.Duration) time.Duration {
+	if retryAfter > p.MaxDelay {
+		return p.MaxDelay
+	}
+	if retryAfter > 0 {
+		return retryAfter
+	}
+	backoff := p.BaseDelay << i
+	if backoff > p.MaxDelay || backoff <= 0 {
+		backoff = p.MaxDelay
+	}
+	if backoff <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(backoff)) + 1)
+}
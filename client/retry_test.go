@@ -0,0 +1,156 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rwsargent/boardbots-go/client/fakeserver"
+	"github.com/rwsargent/boardbots-go/lockitdown"
+	"github.com/stretchr/testify/assert"
+)
+
+// flakyServer fails the first failures requests with status, then succeeds.
+func flakyServer(failures, status int, header http.Header) (*httptest.Server, *int) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		if requests <= failures {
+			for key, values := range header {
+				w.Header()[key] = values
+			}
+			w.WriteHeader(status)
+			return
+		}
+		w.Write([]byte(`{"id": 7, "name": "flaky"}`))
+	}))
+	return server, &requests
+}
+
+func testClient(t *testing.T, addr string) *BoardBotClient[lockitdown.TransportState] {
+	bbClient, err := NewBoardBotClient[lockitdown.TransportState](Credentials{Username: "flaky"}, addr)
+	assert.Nil(t, err)
+	bbClient.Retry = RetryPolicy{Attempts: 4, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+	return bbClient
+}
+
+func TestRetry(t *testing.T) {
+	testcases := []struct {
+		name     string
+		failures int
+		status   int
+		requests int
+		kind     ErrorKind
+		err      bool
+	}{
+		{"recovers", 2, http.StatusServiceUnavailable, 3, 0, false},
+		{"gives up", 10, http.StatusBadGateway, 4, UnavailableError, true},
+		{"auth is permanent", 10, http.StatusUnauthorized, 1, AuthError, true},
+		{"rejection is permanent", 10, http.StatusBadRequest, 1, RejectedError, true},
+		{"server errors are permanent", 10, http.StatusInternalServerError, 1, ServerError, true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			server, requests := flakyServer(tc.failures, tc.status, nil)
+			defer server.Close()
+
+			_, err := GetString(context.Background(), testClient(t, server.URL), "/user")
+			assert.Equal(t, tc.requests, *requests)
+			if !tc.err {
+				assert.Nil(t, err)
+				return
+			}
+			var clientErr *Error
+			assert.True(t, errors.As(err, &clientErr))
+			assert.Equal(t, tc.kind, clientErr.Kind)
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	server, requests := flakyServer(1, http.StatusTooManyRequests, http.Header{"Retry-After": {"1"}})
+	defer server.Close()
+
+	bbClient := testClient(t, server.URL)
+	bbClient.Retry.MaxDelay = 2 * time.Second
+	start := time.Now()
+	_, err := GetString(context.Background(), bbClient, "/user")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, *requests)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+}
+
+func TestRetryAfterIsCapped(t *testing.T) {
+	server, requests := flakyServer(1, http.StatusTooManyRequests, http.Header{"Retry-After": {"86400"}})
+	defer server.Close()
+
+	// The client waits MaxDelay, not a day.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err := GetString(ctx, testClient(t, server.URL), "/user")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, *requests)
+
+	policy := RetryPolicy{Attempts: 3, BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+	assert.Equal(t, 10*time.Second, policy.delay(0, 24*time.Hour))
+	assert.Equal(t, 3*time.Second, policy.delay(0, 3*time.Second))
+}
+
+func TestRetryCancelled(t *testing.T) {
+	server, requests := flakyServer(10, http.StatusServiceUnavailable, http.Header{"Retry-After": {"60"}})
+	defer server.Close()
+
+	bbClient := testClient(t, server.URL)
+	bbClient.Retry.MaxDelay = time.Minute
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := GetString(ctx, bbClient, "/user")
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, *requests)
+}
+
+func TestPostIsNotRetried(t *testing.T) {
+	server, requests := flakyServer(1, http.StatusServiceUnavailable, nil)
+	defer server.Close()
+
+	_, err := Post[Empty, User](context.Background(), testClient(t, server.URL), "/api/lobby/create", Empty{})
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, *requests)
+}
+
+func TestNetworkError(t *testing.T) {
+	server, _ := flakyServer(0, 0, nil)
+	server.Close()
+
+	bbClient := testClient(t, server.URL)
+	bbClient.Retry.Attempts = 1
+	err := bbClient.Authenticate(context.Background())
+	var clientErr *Error
+	assert.True(t, errors.As(err, &clientErr))
+	assert.Equal(t, NetworkError, clientErr.Kind)
+}
+
+func TestIllegalMoveIsRejected(t *testing.T) {
+	server := fakeserver.New(fakeserver.DefaultGameDef)
+	defer server.Close()
+
+	host, _, game := startTestGame(t, server)
+	_, err := host.MakeMove(context.Background(), game.Id.String(), MoveCommand{
+		Json: MoveT{Player: 1, Action: "Advance"},
+	})
+	var clientErr *Error
+	assert.True(t, errors.As(err, &clientErr))
+	assert.Equal(t, RejectedError, clientErr.Kind)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	assert.Equal(t, time.Duration(0), parseRetryAfter(""))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("soon"))
+	assert.Equal(t, 3*time.Second, parseRetryAfter("3"))
+	date := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	assert.InDelta(t, time.Minute, parseRetryAfter(date), float64(2*time.Second))
+}
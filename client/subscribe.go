@@ -0,0 +1,161 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// subscribe.go delivers game updates as they happen, instead of callers
+// polling the game endpoint.
+
+type (
+	// GameUpdate is delivered by Subscribe every time a game changes. Err is
+	// set when an update could not be fetched, the subscription keeps going.
+	GameUpdate[S any] struct {
+		Game Game[S]
+		Err  error
+	}
+)
+
+var (
+	// PollInterval is the minimum time between two requests when the server
+	// supports neither server-sent events nor long polling.
+	PollInterval = 3 * time.Second
+
+	errStreamUnsupported = errors.New("server does not support game event streams")
+)
+
+// Subscribe delivers the current state of the game, and then every change
+// to it until the game is finished or the context is cancelled. The channel
+// is closed when the subscription ends.
+//
+// Updates are streamed as server-sent events from /api/game/{id}/events,
+// until the server ends the stream once the game is over. If the server
+// doesn't support them, or the stream ends before the game does, Subscribe
+// falls back to long polling /api/game/{id}?since={numMoves}, which degrades
+// to polling every PollInterval on servers that answer immediately.
+func (c *BoardBotClient[S]) Subscribe(ctx context.Context, gameId string) <-chan GameUpdate[S] {
+	updates := make(chan GameUpdate[S])
+	go func() {
+		defer close(updates)
+		last, finished, err := c.streamEvents(ctx, gameId, updates)
+		if finished || ctx.Err() != nil {
+			return
+		}
+		if err != nil && !errors.Is(err, errStreamUnsupported) {
+			if !send(ctx, updates, GameUpdate[S]{Err: err}) {
+				return
+			}
+		}
+		c.longPoll(ctx, gameId, last, updates)
+	}()
+	return updates
+}
+
+// streamEvents reads server-sent events until the server closes the stream.
+// Returns the number of moves in the last delivered update, and whether it
+// finished the game.
+func (c *BoardBotClient[S]) streamEvents(ctx context.Context, gameId string, updates chan<- GameUpdate[S]) (last int, finished bool, err error) {
+	last = -1
+	req, err := http.NewRequestWithContext(ctx, "GET", c.domain+fmt.Sprintf("/api/game/%s/events", gameId), nil)
+	if err != nil {
+		return last, false, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return last, false, &Error{Kind: NetworkError, Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		return last, false, errStreamUnsupported
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "data:") {
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+			continue
+		}
+		if line != "" || data.Len() == 0 {
+			// Ignore comments, and event fields other than data.
+			continue
+		}
+
+		var game Game[S]
+		if err := json.Unmarshal([]byte(data.String()), &game); err != nil {
+			return last, false, err
+		}
+		data.Reset()
+		if !send(ctx, updates, GameUpdate[S]{Game: game}) {
+			return last, false, nil
+		}
+		last, finished = game.NumMoves, game.Finished()
+	}
+	return last, finished, scanner.Err()
+}
+
+// longPoll requests the game until it is finished or the context is
+// cancelled, delivering it whenever the number of moves changes.
+func (c *BoardBotClient[S]) longPoll(ctx context.Context, gameId string, last int, updates chan<- GameUpdate[S]) {
+	for {
+		start := time.Now()
+		game, err := c.pollGame(ctx, gameId, last)
+		if ctx.Err() != nil {
+			return
+		}
+
+		changed := err == nil && (game.NumMoves != last || game.Finished())
+		if err != nil || changed {
+			if !send(ctx, updates, GameUpdate[S]{Game: game, Err: err}) {
+				return
+			}
+		}
+		if changed && game.Finished() {
+			return
+		}
+		if changed {
+			last = game.NumMoves
+			continue
+		}
+
+		// The server answered without waiting for a change, don't hammer it.
+		if wait := PollInterval - time.Since(start); wait > 0 {
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+func (c *BoardBotClient[S]) pollGame(ctx context.Context, gameId string, since int) (Game[S], error) {
+	var game Game[S]
+	body, err := c.roundTrip(ctx, "GET", fmt.Sprintf("/api/game/%s?since=%d", gameId, since), nil)
+	if err != nil {
+		return game, err
+	}
+	err = json.Unmarshal(body, &game)
+	return game, err
+}
+
+func send[S any](ctx context.Context, updates chan<- GameUpdate[S], update GameUpdate[S]) bool {
+	select {
+	case updates <- update:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
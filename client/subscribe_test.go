@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rwsargent/boardbots-go/client/fakeserver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscribe(t *testing.T) {
+	testcases := []struct {
+		name          string
+		disableEvents bool
+	}{
+		{"events", false},
+		{"long poll", true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := fakeserver.New(fakeserver.DefaultGameDef)
+			server.DisableEvents = tc.disableEvents
+			defer server.Close()
+
+			host, oppo, game := startTestGame(t, server)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			updates := oppo.Subscribe(ctx, game.Id.String())
+
+			initial := <-updates
+			assert.Nil(t, initial.Err)
+			assert.Equal(t, 0, initial.Game.NumMoves)
+			assert.Equal(t, 1, initial.Game.State.PlayerTurn)
+
+			moves, err := host.GetPossibleMoves(context.Background(), game.Id.String())
+			assert.Nil(t, err)
+			state, err := host.MakeMove(context.Background(), game.Id.String(), MoveCommand{Json: moves[0]})
+			assert.Nil(t, err)
+
+			start := time.Now()
+			update := <-updates
+			assert.Nil(t, update.Err)
+			assert.Equal(t, 1, update.Game.NumMoves)
+			assert.Equal(t, state, update.Game.State)
+			// Pushed, rather than picked up by the next poll.
+			assert.Less(t, time.Since(start), PollInterval)
+
+			cancel()
+			for range updates {
+			}
+		})
+	}
+}
+
+func TestSubscribeEndsWithGame(t *testing.T) {
+	testcases := []struct {
+		name            string
+		disableEvents   bool
+		maxStreamEvents int
+	}{
+		{"events", false, 0},
+		{"long poll", true, 0},
+		{"stream cut short", false, 1},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := fakeserver.New(fakeserver.DefaultGameDef)
+			server.DisableEvents = tc.disableEvents
+			server.MaxStreamEvents = tc.maxStreamEvents
+			defer server.Close()
+
+			host, oppo, game := startTestGame(t, server)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			updates := oppo.Subscribe(ctx, game.Id.String())
+			initial := <-updates
+			assert.Nil(t, initial.Err)
+
+			moves, err := host.GetPossibleMoves(context.Background(), game.Id.String())
+			assert.Nil(t, err)
+			_, err = host.MakeMove(context.Background(), game.Id.String(), MoveCommand{Json: moves[0]})
+			assert.Nil(t, err)
+			update := <-updates
+			assert.Nil(t, update.Err)
+			assert.Equal(t, 1, update.Game.NumMoves)
+
+			won := server.GameState(game.Id)
+			won.Winner = 0
+			assert.Nil(t, server.SetGameState(game.Id, won))
+			update = <-updates
+			assert.Nil(t, update.Err)
+			assert.True(t, update.Game.Finished())
+
+			_, open := <-updates
+			assert.False(t, open, "subscription outlived the game")
+			assert.Nil(t, ctx.Err())
+		})
+	}
+}
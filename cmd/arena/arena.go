@@ -0,0 +1,285 @@
+// Arena plays LockItDown engines against each other in-process, without a
+// boardbots server. Every pair of engines plays -games games, alternating
+// seats, and the results are reported as win/draw/loss tables with Elo
+// estimates.
+//
+// $> arena -engine=name=fast,depth=2 -engine=name=deep,depth=4 -games=100
+//
+// Engines are comma separated key=value options: name, search (alphabeta,
+// minimax, random), eval, depth and movetime.
+//
+// With -sprt, exactly two engines are given, a baseline followed by a
+// candidate, and games are played until a sequential probability ratio test
+// accepts either H0 (the candidate gains -elo0) or H1 (it gains -elo1).
+//
+// $> arena -sprt -elo0=0 -elo1=20 -engine=name=base,depth=3 -engine=name=cand,depth=3,eval=new
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/rwsargent/boardbots-go/internal/elo"
+	"github.com/rwsargent/boardbots-go/lockitdown"
+)
+
+type (
+	// Outcome of a single game from the first seat's point of view.
+	Outcome int
+
+	// Pairing is one engine matched against another. Results are kept from
+	// the first engine's point of view.
+	Pairing struct {
+		First, Second Engine
+		Result        elo.Result
+		Aborted       int
+	}
+
+	game struct {
+		pairing *Pairing
+		opening int64
+		// Seat of the first engine in the pairing.
+		firstSeat int
+	}
+
+	arenaConfig struct {
+		gameDef     lockitdown.GameDef
+		maxActions  int
+		randomPlies int
+	}
+)
+
+const (
+	Draw Outcome = iota
+	FirstSeatWins
+	SecondSeatWins
+	Aborted
+)
+
+func main() {
+	var engines engineFlags
+	flag.Var(&engines, "engine", "Engine options, may be repeated. e.g. name=deep,search=alphabeta,eval=default,depth=4,movetime=2s")
+	games := flag.Int("games", 20, "Games per pair of engines. Rounded up to an even number so each engine plays both seats.")
+	concurrency := flag.Int("concurrency", runtime.NumCPU(), "Number of games to play in parallel.")
+	seed := flag.Int64("seed", 1, "Seed for openings and random engines.")
+	randomPlies := flag.Int("random-plies", 4, "Number of random actions played at the start of each opening.")
+	maxActions := flag.Int("max-actions", 600, "Number of actions after which a game is declared a draw.")
+	radius := flag.Int("radius", 4, "Arena radius.")
+	robots := flag.Int("robots", 6, "Robots per player.")
+	sprt := flag.Bool("sprt", false, "Run a sequential probability ratio test of the second engine (candidate) against the first (baseline).")
+	elo0 := flag.Float64("elo0", 0, "SPRT: Elo gain of the candidate under the null hypothesis.")
+	elo1 := flag.Float64("elo1", 10, "SPRT: Elo gain of the candidate under the alternative hypothesis.")
+	alpha := flag.Float64("alpha", 0.05, "SPRT: probability of accepting H1 when H0 is true.")
+	beta := flag.Float64("beta", 0.05, "SPRT: probability of accepting H0 when H1 is true.")
+	maxGames := flag.Int("max-games", 20000, "SPRT: number of games after which the test gives up.")
+
+	flag.Parse()
+
+	if len(engines) < 2 {
+		fmt.Println("Require at least two engines")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *concurrency < 1 {
+		fmt.Printf("Concurrency must be at least 1, is %d\n", *concurrency)
+		os.Exit(2)
+	}
+
+	names := make(map[string]bool)
+	for _, engine := range engines {
+		if names[engine.Name] {
+			fmt.Printf("Engine names must be unique, %q is repeated\n", engine.Name)
+			os.Exit(2)
+		}
+		names[engine.Name] = true
+	}
+
+	config := arenaConfig{
+		gameDef: lockitdown.GameDef{
+			Board:           lockitdown.Board{HexaBoard: lockitdown.BoardType{ArenaRadius: *radius}},
+			Players:         2,
+			MovesPerTurn:    3,
+			RobotsPerPlayer: *robots,
+			WinCondition:    "Elimination",
+		},
+		maxActions:  *maxActions,
+		randomPlies: *randomPlies,
+	}
+
+	if *sprt {
+		if len(engines) != 2 {
+			fmt.Println("SPRT requires exactly two engines, the baseline followed by the candidate")
+			os.Exit(2)
+		}
+		test := elo.SPRT{Elo0: *elo0, Elo1: *elo1, Alpha: *alpha, Beta: *beta}
+		if err := test.Validate(); err != nil {
+			fmt.Printf("Invalid SPRT flags, %s\n", err)
+			os.Exit(2)
+		}
+		runSPRT(config, engines[0], engines[1], test, *seed, *concurrency, *maxGames)
+		return
+	}
+
+	pairings := make([]*Pairing, 0)
+	for i := 0; i < len(engines); i++ {
+		for j := i + 1; j < len(engines); j++ {
+			pairings = append(pairings, &Pairing{First: engines[i], Second: engines[j]})
+		}
+	}
+
+	jobs := make(chan game)
+	go func() {
+		defer close(jobs)
+		for opening := 0; opening < (*games+1)/2; opening++ {
+			for _, pairing := range pairings {
+				for seat := 0; seat < 2; seat++ {
+					jobs <- game{
+						pairing:   pairing,
+						opening:   *seed + int64(opening),
+						firstSeat: seat,
+					}
+				}
+			}
+		}
+	}()
+
+	config.playAll(jobs, *concurrency, func(g game, outcome Outcome) {
+		g.pairing.record(outcome, g.firstSeat)
+	})
+
+	printPairings(pairings)
+	printStandings(engines, pairings)
+}
+
+// playAll plays every game sent on jobs with a pool of workers, and returns
+// once jobs is closed and drained. record is called with the outcome of each
+// game, and never concurrently.
+func (config arenaConfig) playAll(jobs <-chan game, concurrency int, record func(game, Outcome)) {
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for g := range jobs {
+				outcome := config.play(g)
+				lock.Lock()
+				record(g, outcome)
+				lock.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// play runs a single game to completion. The opening is derived from the
+// game's seed so both seats of a pairing play from the same position.
+func (config arenaConfig) play(g game) (outcome Outcome) {
+	defer func() {
+		// A panicking engine aborts its game, not the whole match.
+		if r := recover(); r != nil {
+			fmt.Fprintf(os.Stderr, "aborting game, engine panicked: %v\n%s\n", r, debug.Stack())
+			outcome = Aborted
+		}
+	}()
+
+	seats := [2]Engine{g.pairing.First, g.pairing.Second}
+	if g.firstSeat == 1 {
+		seats[0], seats[1] = seats[1], seats[0]
+	}
+
+	state := lockitdown.NewGame(config.gameDef)
+	rng := rand.New(rand.NewSource(g.opening))
+	for ply := 0; ply < config.randomPlies; ply++ {
+		moves := state.PossibleMoves([]lockitdown.GameMove{})
+		if len(moves) == 0 {
+			return Draw
+		}
+		if err := state.Move(&moves[rng.Intn(len(moves))]); err != nil {
+			return gameOver(state, err)
+		}
+	}
+
+	for actions := 0; actions < config.maxActions; actions++ {
+		move, found := seats[state.PlayerTurn].ChooseMove(state, rng)
+		if !found {
+			return Draw
+		}
+		if err := state.Move(&move); err != nil {
+			return gameOver(state, err)
+		}
+	}
+	return Draw
+}
+
+// gameOver converts the error of the final move into an outcome.
+func gameOver(state *lockitdown.GameState, err error) Outcome {
+	switch state.Winner {
+	case 0:
+		return FirstSeatWins
+	case 1:
+		return SecondSeatWins
+	}
+	fmt.Fprintf(os.Stderr, "aborting game: %s\n", err)
+	return Aborted
+}
+
+func (p *Pairing) record(outcome Outcome, firstSeat int) {
+	if outcome == Aborted {
+		p.Aborted++
+		return
+	}
+	if outcome == Draw {
+		p.Result.Draws++
+		return
+	}
+	firstWon := (outcome == FirstSeatWins) == (firstSeat == 0)
+	if firstWon {
+		p.Result.Wins++
+	} else {
+		p.Result.Losses++
+	}
+}
+
+func printPairings(pairings []*Pairing) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "engine\topponent\tgames\twins\tdraws\tlosses\taborted\tscore\telo\t")
+	for _, p := range pairings {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%d\t%d\t%.1f%%\t%s\t\n",
+			p.First, p.Second, p.Result.Games(), p.Result.Wins, p.Result.Draws, p.Result.Losses,
+			p.Aborted, p.Result.Score()*100, formatElo(p.Result))
+	}
+	w.Flush()
+	fmt.Println()
+}
+
+// printStandings reports each engine's combined result against the field.
+func printStandings(engines []Engine, pairings []*Pairing) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "engine\tgames\twins\tdraws\tlosses\tscore\telo vs field\t")
+	for _, engine := range engines {
+		var total elo.Result
+		for _, p := range pairings {
+			if p.First.Name == engine.Name {
+				total = total.Add(p.Result)
+			} else if p.Second.Name == engine.Name {
+				total = total.Add(p.Result.Flip())
+			}
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%.1f%%\t%s\t\n",
+			engine, total.Games(), total.Wins, total.Draws, total.Losses, total.Score()*100, formatElo(total))
+	}
+	w.Flush()
+}
+
+func formatElo(result elo.Result) string {
+	diff, margin := result.Estimate()
+	return fmt.Sprintf("%+.1f ± %.1f", diff, margin)
+}
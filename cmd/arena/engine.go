@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rwsargent/boardbots-go/lockitdown"
+)
+
+type (
+	// Engine describes how a player in the arena picks its moves.
+	Engine struct {
+		Name      string
+		Search    string
+		Evaluator string
+		Depth     int
+		MoveTime  time.Duration
+	}
+
+	// engineFlags collects repeated -engine flags.
+	engineFlags []Engine
+)
+
+const (
+	SearchAlphaBeta = "alphabeta"
+	SearchMinimax   = "minimax"
+	SearchRandom    = "random"
+)
+
+// ParseEngine reads an engine from a comma separated list of key=value
+// pairs, for example "name=deep,search=alphabeta,eval=default,depth=4,movetime=2s".
+func ParseEngine(spec string) (Engine, error) {
+	engine := Engine{
+		Search:    SearchAlphaBeta,
+		Evaluator: "default",
+		Depth:     3,
+	}
+	for _, field := range strings.Split(spec, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(field), "=")
+		if !found {
+			return engine, fmt.Errorf("engine option %q is not a key=value pair", field)
+		}
+		switch key {
+		case "name":
+			engine.Name = value
+		case "search":
+			engine.Search = value
+		case "eval":
+			engine.Evaluator = value
+		case "depth":
+			depth, err := strconv.Atoi(value)
+			if err != nil || depth < 1 {
+				return engine, fmt.Errorf("invalid depth %q", value)
+			}
+			engine.Depth = depth
+		case "movetime":
+			moveTime, err := time.ParseDuration(value)
+			if err != nil {
+				return engine, fmt.Errorf("invalid movetime %q: %w", value, err)
+			}
+			engine.MoveTime = moveTime
+		default:
+			return engine, fmt.Errorf("unknown engine option %q", key)
+		}
+	}
+
+	switch engine.Search {
+	case SearchAlphaBeta, SearchMinimax, SearchRandom:
+	default:
+		return engine, fmt.Errorf("unknown search %q", engine.Search)
+	}
+	if _, found := lockitdown.Evaluators[engine.Evaluator]; !found {
+		return engine, fmt.Errorf("unknown evaluator %q", engine.Evaluator)
+	}
+	if engine.Name == "" {
+		engine.Name = fmt.Sprintf("%s-%s-d%d", engine.Search, engine.Evaluator, engine.Depth)
+		if engine.MoveTime > 0 {
+			engine.Name += "-" + engine.MoveTime.String()
+		}
+	}
+	return engine, nil
+}
+
+func (e Engine) String() string {
+	return e.Name
+}
+
+// ChooseMove picks a move for the player whose turn it is. Returns false
+// if the player has no moves.
+func (e Engine) ChooseMove(game *lockitdown.GameState, rng *rand.Rand) (lockitdown.GameMove, bool) {
+	if e.Search == SearchRandom {
+		moves := game.PossibleMoves([]lockitdown.GameMove{})
+		if len(moves) == 0 {
+			return lockitdown.GameMove{}, false
+		}
+		return moves[rng.Intn(len(moves))], true
+	}
+
+	root := &lockitdown.MinimaxNode{
+		GameState: game,
+		GameMove:  lockitdown.GameMove{},
+		Searcher:  game.PlayerTurn,
+		Evaluator: lockitdown.Evaluators[e.Evaluator],
+	}
+
+	var best lockitdown.MinimaxNode
+	switch {
+	case e.Search == SearchMinimax:
+		best = lockitdown.MinimaxWithIterator(root, e.Depth)
+	case e.MoveTime > 0:
+		best = iterativeDeepening(root, e.Depth, e.MoveTime)
+	default:
+		best = lockitdown.AlphaBeta(context.Background(), root, e.Depth)
+	}
+	return best.GameMove, best.GameMove.Mover != nil
+}
+
+// iterativeDeepening searches one ply deeper at a time until maxDepth or the
+// move time runs out, keeping the result of the last completed depth.
+func iterativeDeepening(root *lockitdown.MinimaxNode, maxDepth int, moveTime time.Duration) lockitdown.MinimaxNode {
+	ctx, cancel := context.WithTimeout(context.Background(), moveTime)
+	defer cancel()
+
+	var best lockitdown.MinimaxNode
+	for depth := 1; depth <= maxDepth; depth++ {
+		result := lockitdown.AlphaBeta(ctx, root, depth)
+		if ctx.Err() != nil && depth > 1 {
+			break
+		}
+		best = result
+	}
+	return best
+}
+
+func (f *engineFlags) String() string {
+	names := make([]string, len(*f))
+	for i, engine := range *f {
+		names[i] = engine.Name
+	}
+	return strings.Join(names, " ")
+}
+
+func (f *engineFlags) Set(spec string) error {
+	engine, err := ParseEngine(spec)
+	if err != nil {
+		return err
+	}
+	*f = append(*f, engine)
+	return nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/rwsargent/boardbots-go/internal/elo"
+)
+
+// runSPRT plays the candidate against the baseline until the test accepts
+// a hypothesis, or maxGames have been played. Progress is printed after
+// every game.
+func runSPRT(config arenaConfig, baseline, candidate Engine, test elo.SPRT, seed int64, concurrency, maxGames int) {
+	pairing := &Pairing{First: candidate, Second: baseline}
+	lower, upper := test.Bounds()
+	fmt.Printf("SPRT %s vs %s: elo0=%.1f elo1=%.1f alpha=%.3f beta=%.3f bounds=[%.2f, %.2f]\n",
+		candidate, baseline, test.Elo0, test.Elo1, test.Alpha, test.Beta, lower, upper)
+
+	jobs := make(chan game)
+	done := make(chan struct{})
+	go func() {
+		defer close(jobs)
+		for opening := int64(0); ; opening++ {
+			for seat := 0; seat < 2; seat++ {
+				select {
+				case jobs <- game{pairing: pairing, opening: seed + opening, firstSeat: seat}:
+				case <-done:
+					return
+				}
+			}
+		}
+	}()
+
+	decision := elo.Continue
+	stopped := false
+	config.playAll(jobs, concurrency, func(g game, outcome Outcome) {
+		// Games still in flight when the test stopped don't count.
+		if stopped {
+			return
+		}
+		pairing.record(outcome, g.firstSeat)
+		result := pairing.Result
+		decision = test.Test(result)
+		fmt.Printf("games %d  W %d  D %d  L %d  aborted %d  llr %.2f [%.2f, %.2f]  elo %s\n",
+			result.Games(), result.Wins, result.Draws, result.Losses, pairing.Aborted,
+			test.LLR(result), lower, upper, formatElo(result))
+
+		if decision != elo.Continue || result.Games()+pairing.Aborted >= maxGames {
+			stopped = true
+			close(done)
+		}
+	})
+
+	fmt.Println()
+	printPairings([]*Pairing{pairing})
+	if decision == elo.Continue {
+		fmt.Printf("SPRT inconclusive after %d games\n", maxGames)
+		return
+	}
+	fmt.Printf("SPRT %s\n", decision)
+}
@@ -0,0 +1,45 @@
+// Lockitdown-engine plays lockitdown over stdin and stdout, in the text
+// protocol of the engine package, for GUIs and tournament managers.
+//
+// $> lockitdown-engine -eval=default
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+
+	"github.com/rwsargent/boardbots-go/engine"
+	"github.com/rwsargent/boardbots-go/lockitdown"
+)
+
+func main() {
+	eval := flag.String("eval", "default", "Evaluator scoring positions.")
+
+	flag.Parse()
+
+	evaluator, found := lockitdown.Evaluators[*eval]
+	if !found {
+		names := make([]string, 0, len(lockitdown.Evaluators))
+		for name := range lockitdown.Evaluators {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Fprintf(os.Stderr, "unknown evaluator %q, expected one of %v\n", *eval, names)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	e := engine.New(os.Stdout)
+	e.Evaluator = evaluator
+	if err := e.Run(ctx, os.Stdin); err != nil && err != context.Canceled {
+		fmt.Fprintf(os.Stderr, "engine stopped, %s\n", err.Error())
+		os.Exit(1)
+	}
+}
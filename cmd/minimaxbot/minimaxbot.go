@@ -4,22 +4,32 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/rwsargent/boardbots-go/bot"
 	"github.com/rwsargent/boardbots-go/client"
-	"github.com/rwsargent/boardbots-go/internal"
 	"github.com/rwsargent/boardbots-go/lockitdown"
+	"github.com/rwsargent/boardbots-go/metrics"
 )
 
 func main() {
 	server := flag.String("server", "http://localhost:8080", "Host of the boardbots server to play on.")
 	username := flag.String("username", "", "Username")
 	gameId := flag.String("gameId", "", "Game ID")
+	daemon := flag.Bool("daemon", false, "Play every game of the account, until terminated, instead of one game.")
+	join := flag.String("join", "", "In daemon mode, join open lobbies of this game type.")
+	depth := flag.Int("depth", 10, "Maximum depth of the search.")
+	moveTime := flag.Duration("movetime", 10*time.Second, "Time to search for each move.")
+	metricsAddr := flag.String("metrics-addr", "", "Address to serve Prometheus metrics on, at /metrics. Not served if empty.")
 
 	flag.Parse()
 
-	if *gameId == "" || *username == "" {
+	if (*gameId == "" && !*daemon) || *username == "" {
 		fmt.Println("Require a game ID and username")
+		return
 	}
 
 	bbClient, err := client.NewBoardBotClient[lockitdown.TransportState](client.Credentials{
@@ -31,70 +41,38 @@ func main() {
 		return
 	}
 
-	err = bbClient.Authenticate()
-	if err != nil {
-		fmt.Printf("could not authenticate %s", err.Error())
-	}
-
-	tGame, err := bbClient.Game(*gameId)
-	if err != nil {
-		panic(err)
+	var policy bot.Policy[lockitdown.TransportState] = bot.Minimax{
+		Depth:     *depth,
+		MoveTime:  *moveTime,
+		Evaluator: lockitdown.ScoreGameState,
 	}
-	playerPosition := getPlayerPosition(tGame, bbClient.Credentials.Username)
-
-	game := lockitdown.StateFromTransport(&tGame.State)
-
-	for game.Winner < 0 {
-		if playerPosition-1 != int(game.PlayerTurn) {
-			fmt.Println("waiting for turn")
-			time.Sleep(time.Second * 3)
-
-			tGame, err := bbClient.Game(*gameId)
-			if err != nil {
-				panic(err)
+	if *metricsAddr != "" {
+		registry := metrics.NewRegistry()
+		policy = bot.Measure(policy, registry)
+		go func() {
+			if err := metrics.ListenAndServe(*metricsAddr, registry); err != nil {
+				fmt.Printf("failed to serve metrics, %s\n", err.Error())
 			}
-
-			game = lockitdown.StateFromTransport(&tGame.State)
-			continue
-		}
-
-		root := &lockitdown.MinimaxNode{
-			GameState: game,
-			GameMove:  lockitdown.GameMove{},
-			Searcher:  lockitdown.PlayerPosition(playerPosition - 1),
-			Evaluator: lockitdown.ScoreGameState,
-		}
-
-		ctx, cancelFunc := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancelFunc()
-		best := lockitdown.AlphaBeta(ctx, root, 10)
-
-		fmt.Printf("%s making move: %+v\n", bbClient.Credentials.Username, best.GameMove)
-		movet := best.GameMove.ToTransport()
-		moveCommand := client.MoveCommand{
-			Json: client.MoveT{
-				Player: playerPosition,
-				Pos: internal.Position{
-					Q: movet.Position.Q,
-					R: movet.Position.R,
-				},
-				Action: movet.Action,
-			},
+		}()
+	}
+	runner := bot.NewRunner[lockitdown.TransportState](bbClient, bot.LockItDown, policy)
+
+	if *daemon {
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+		defer stop()
+		d := bot.NewDaemon(runner)
+		if *join != "" {
+			d.JoinLobby = bot.GameType(*join)
 		}
-		fmt.Printf("Move Command: %v\n", moveCommand)
-		state, err := bbClient.MakeMove(*gameId, moveCommand)
-		if err != nil {
+		if err := d.Run(ctx); err != nil {
 			panic(err)
 		}
-		game = lockitdown.StateFromTransport(&state)
+		return
 	}
-}
 
-func getPlayerPosition[S any](game client.Game[S], username string) int {
-	for idx, user := range game.Players {
-		if user.Name == username {
-			return idx + 1
-		}
+	game, err := runner.Play(context.Background(), *gameId)
+	if err != nil {
+		panic(err)
 	}
-	return -1
+	fmt.Printf("game over, %v\n", game.State.Status)
 }
@@ -0,0 +1,97 @@
+// Quoridorbot plays quoridor against itself with an alpha-beta search, printing the board after every move. It only
+// plays locally: boardbots servers have no move format for quoridor, so there is nothing for the client to send, and a
+// bot.Runner for quoridor would only be guessing at one.
+//
+// $> quoridorbot -depth=2
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rwsargent/boardbots-go/quoridor"
+)
+
+func main() {
+	depth := flag.Int("depth", 3, "Depth of the search.")
+	eval := flag.String("eval", "default", "Evaluator scoring positions.")
+	maxMoves := flag.Int("maxmoves", 200, "Stop the game after this many moves.")
+
+	flag.Parse()
+
+	evaluator, found := quoridor.Evaluators[*eval]
+	if !found {
+		fmt.Printf("unknown evaluator %q\n", *eval)
+		return
+	}
+
+	if err := playLocal(*depth, evaluator, *maxMoves); err != nil {
+		fmt.Printf("local game failed, %s\n", err.Error())
+	}
+}
+
+// playLocal plays a two player game, the bot making the moves of both players.
+func playLocal(depth int, evaluator quoridor.Evaluator, maxMoves int) error {
+	game, err := quoridor.NewGame(uuid.New(), "local")
+	if err != nil {
+		return err
+	}
+	for _, name := range []string{"one", "two"} {
+		if _, err := game.AddPlayer(uuid.New(), name); err != nil {
+			return err
+		}
+	}
+	if err := game.StartGame(); err != nil {
+		return err
+	}
+
+	fmt.Println(formatBoard(game))
+	for i := 0; i < maxMoves && !game.IsOver(); i++ {
+		move, score, found := quoridor.BestMove(game, depth, evaluator)
+		if !found {
+			return fmt.Errorf("player %d has no moves", game.CurrentTurn)
+		}
+		if err := game.Apply(move); err != nil {
+			return err
+		}
+		fmt.Printf("move %d, player %d: %s, score %d\n%s\n", i+1, move.Player, move, score, formatBoard(game))
+	}
+	if game.IsOver() {
+		fmt.Printf("player %d won\n", game.Winner)
+	} else {
+		fmt.Printf("no winner after %d moves\n", maxMoves)
+	}
+	return nil
+}
+
+// formatBoard draws the board the way quoridor.BuildQuoridorBoardFromString reads it.
+func formatBoard(game *quoridor.Game) string {
+	var b strings.Builder
+	for y := 0; y < quoridor.BoardSize; y++ {
+		for x := 0; x < quoridor.BoardSize; x++ {
+			position := quoridor.Position{X: x, Y: y}
+			piece, found := game.Board[position]
+			switch {
+			case !found:
+				b.WriteByte('.')
+			case piece.Type == quoridor.Pawn:
+				b.WriteByte(byte('0' + piece.Owner))
+			case y%2 == 1 && x%2 == 1:
+				// The middle of a barrier, horizontal if the cells beside it are taken.
+				if _, left := game.Board[quoridor.Position{X: x - 1, Y: y}]; left {
+					b.WriteByte('-')
+				} else {
+					b.WriteByte('|')
+				}
+			case y%2 == 1:
+				b.WriteByte('-')
+			default:
+				b.WriteByte('|')
+			}
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
@@ -3,39 +3,41 @@
 //
 // $> randobot -username=randobot -gameId=00000000-...-0000 -server=https://boardbots.dev
 //
-// It is recommend to run setupbots before using randobot.
+// It is recommend to run setupbots before using randobot. To play every game
+// of the account, and join open lockitdown lobbies, until terminated:
+//
+// $> randobot -username=randobot -daemon -join=lockitdown -server=https://boardbots.dev
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
-	"math/rand"
-	"time"
+	"os"
+	"os/signal"
+	"syscall"
 
+	"github.com/rwsargent/boardbots-go/bot"
 	"github.com/rwsargent/boardbots-go/client"
-	"github.com/rwsargent/boardbots-go/internal"
 	"github.com/rwsargent/boardbots-go/lockitdown"
+	"github.com/rwsargent/boardbots-go/metrics"
 )
 
-var edges = []struct{ pos, dir internal.Position }{
-	{pos: internal.Position{Q: 0, R: -5}, dir: internal.Position{Q: 0, R: 1}},
-	{pos: internal.Position{Q: 5, R: -5}, dir: internal.Position{Q: -1, R: 1}},
-	{pos: internal.Position{Q: 5, R: 0}, dir: internal.Position{Q: -1, R: 0}},
-	{pos: internal.Position{Q: 0, R: 5}, dir: internal.Position{Q: 0, R: -1}},
-	{pos: internal.Position{Q: -5, R: 5}, dir: internal.Position{Q: 1, R: -1}},
-	{pos: internal.Position{Q: -5, R: 0}, dir: internal.Position{Q: 1, R: 0}},
-}
-
 func main() {
 
 	server := flag.String("server", "http://localhost:8080", "Host of the boardbots server to play on.")
 	username := flag.String("username", "", "Username")
 	gameId := flag.String("gameId", "", "Game ID")
+	daemon := flag.Bool("daemon", false, "Play every game of the account, until terminated, instead of one game.")
+	join := flag.String("join", "", "In daemon mode, join open lobbies of this game type.")
+	seed := flag.Int64("seed", 63, "Seed for the random moves.")
+	metricsAddr := flag.String("metrics-addr", "", "Address to serve Prometheus metrics on, at /metrics. Not served if empty.")
 
 	flag.Parse()
 
-	if *gameId == "" || *username == "" {
+	if (*gameId == "" && !*daemon) || *username == "" {
 		fmt.Println("Require a game ID and username")
+		return
 	}
 
 	bbClient, err := client.NewBoardBotClient[lockitdown.TransportState](client.Credentials{
@@ -47,97 +49,34 @@ func main() {
 		return
 	}
 
-	err = bbClient.Authenticate()
-	if err != nil {
-		fmt.Printf("could not authenticate %s", err.Error())
-	}
-
-	tGame, err := bbClient.Game(*gameId)
-	if err != nil {
-		panic(err)
-	}
-	playerPosition := getPlayerPosition(tGame, bbClient.Credentials.Username)
-
-	game := lockitdown.StateFromTransport(&tGame.State)
-
-	rand.Seed(63)
-
-	for game.Winner < 0 {
-		if playerPosition-1 != int(game.PlayerTurn) {
-			fmt.Println("waiting for turn")
-			time.Sleep(time.Second * 3)
-
-			tGame, err := bbClient.Game(*gameId)
-			if err != nil {
-				panic(err)
-			}
-
-			game = lockitdown.StateFromTransport(&tGame.State)
-			continue
-		}
-
-		playerMoves, err := movesForPlayer(bbClient, gameId, playerPosition)
-
-		var moveCommand client.MoveCommand
-
-		if len(playerMoves) == 0 {
-			moveCommand = placeRobotMove(game, playerPosition)
-		} else {
-			moveCommand = client.MoveCommand{
-				Json: playerMoves[rand.Intn(len(playerMoves))],
+	var policy bot.Policy[lockitdown.TransportState] = bot.NewRandom(*seed)
+	if *metricsAddr != "" {
+		registry := metrics.NewRegistry()
+		policy = bot.Measure(policy, registry)
+		go func() {
+			if err := metrics.ListenAndServe(*metricsAddr, registry); err != nil {
+				fmt.Printf("failed to serve metrics, %s\n", err.Error())
 			}
+		}()
+	}
+	runner := bot.NewRunner[lockitdown.TransportState](bbClient, bot.LockItDown, policy)
+
+	if *daemon {
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+		defer stop()
+		d := bot.NewDaemon(runner)
+		if *join != "" {
+			d.JoinLobby = bot.GameType(*join)
 		}
-
-		fmt.Printf("%s making move: %+v\n", bbClient.Credentials.Username, moveCommand)
-		state, err := bbClient.MakeMove(*gameId, moveCommand)
-		if err != nil {
+		if err := d.Run(ctx); err != nil {
 			panic(err)
 		}
-		game = lockitdown.StateFromTransport(&state)
+		return
 	}
-}
 
-func movesForPlayer(bbClient *client.BoardBotClient[lockitdown.TransportState], gameId *string, playerPosition int) ([]client.MoveT, error) {
-	moves, err := bbClient.GetPossibleMoves(*gameId)
+	game, err := runner.Play(context.Background(), *gameId)
 	if err != nil {
 		panic(err)
 	}
-
-	playerMoves := make([]client.MoveT, 0, len(moves))
-
-	for _, move := range moves {
-		if move.Player == playerPosition {
-			playerMoves = append(playerMoves, move)
-		}
-	}
-	return playerMoves, err
-}
-
-func placeRobotMove(game *lockitdown.GameState, playerPosition int) client.MoveCommand {
-	placePosition := edges[rand.Intn(len(edges))]
-
-	for bot := game.RobotAt(lockitdown.Pair{Q: placePosition.pos.Q, R: placePosition.pos.R}); bot != nil; {
-		placePosition = edges[rand.Intn(len(edges))]
-	}
-
-	return client.MoveCommand{
-		Json: client.MoveT{
-			Player: playerPosition,
-			Pos:    placePosition.pos,
-			Action: lockitdown.PlaceRobotT{
-				PlaceRobot: struct {
-					Dir lockitdown.Pair `json:"dir"`
-				}{Dir: lockitdown.Pair{Q: placePosition.dir.Q, R: placePosition.dir.R}},
-			},
-		},
-	}
-}
-
-func getPlayerPosition[S any](game client.Game[S], username string) int {
-	for idx, user := range game.Players {
-		if user.Name == username {
-			return idx + 1
-		}
-	}
-	return -1
+	fmt.Printf("game over, %v\n", game.State.Status)
 }
@@ -0,0 +1,69 @@
+// Rulecheck compares the rules of the local lockitdown engine with a
+// boardbots server, for a recorded game or, with -watch, a live game as it
+// is played. Every position where they disagree is printed.
+//
+// $> rulecheck -username=checker -gameId=00000000-...-0000 -server=https://boardbots.dev -watch
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/rwsargent/boardbots-go/client"
+	"github.com/rwsargent/boardbots-go/lockitdown"
+	"github.com/rwsargent/boardbots-go/rulecheck"
+)
+
+func main() {
+	server := flag.String("server", "http://localhost:8080", "Host of the boardbots server.")
+	username := flag.String("username", "", "Username")
+	gameId := flag.String("gameId", "", "Game ID")
+	watch := flag.Bool("watch", false, "Check every position of the game as it is played.")
+
+	flag.Parse()
+
+	if *gameId == "" || *username == "" {
+		fmt.Println("Require a game ID and username")
+		return
+	}
+
+	bbClient, err := client.NewBoardBotClient[lockitdown.TransportState](client.Credentials{
+		Username: *username,
+	}, *server)
+	if err != nil {
+		fmt.Printf("failed to start client, %s\n", err.Error())
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := bbClient.Authenticate(ctx); err != nil {
+		fmt.Printf("could not authenticate %s\n", err.Error())
+		return
+	}
+
+	found := 0
+	report := func(divergence rulecheck.Divergence) {
+		found++
+		fmt.Println(divergence)
+	}
+	if *watch {
+		err = rulecheck.Watch(ctx, bbClient, *gameId, report)
+	} else {
+		var divergences []rulecheck.Divergence
+		divergences, err = rulecheck.Check(ctx, bbClient, *gameId)
+		for _, divergence := range divergences {
+			report(divergence)
+		}
+	}
+	if err != nil {
+		fmt.Printf("failed to check game, %s\n", err)
+	}
+	fmt.Printf("%d divergences\n", found)
+	if found > 0 || err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"time"
+
+	"github.com/rwsargent/boardbots-go/lockitdown"
+	"github.com/rwsargent/boardbots-go/metrics"
+)
+
+// metrics.go has the metrics of the server, served on /metrics.
+
+// Kinds of errors, counted by errorsTotal.
+const (
+	bodyTooLarge     = "body_too_large"
+	decodeError      = "decode"
+	invalidRequest   = "invalid_request"
+	methodNotAllowed = "method_not_allowed"
+	writeError       = "write"
+)
+
+var (
+	registry = metrics.NewRegistry()
+
+	requestsTotal = registry.Counter("scorer_requests_total",
+		"Requests served, by route and status.", "route", "status")
+	requestDuration = registry.Histogram("scorer_request_duration_seconds",
+		"Time to serve requests, by route.", metrics.DurationBuckets, "route")
+	errorsTotal = registry.Counter("scorer_errors_total",
+		"Requests that failed, by type of error.", "type")
+	batchItemsTotal = registry.Counter("scorer_batch_items_total",
+		"Requests scored in batches, by whether they were ok or an error.", "result")
+	searchDepth = registry.Histogram("scorer_search_depth",
+		"Deepest completed search of each request, by route.", metrics.LinearBuckets(1, 1, maxDepth), "route")
+	searchNodesPerSecond = registry.Histogram("scorer_search_nodes_per_second",
+		"Positions searched per second, by route.", metrics.ExponentialBuckets(10_000, 2, 10), "route")
+	tableProbesTotal = registry.Counter("scorer_search_table_probes_total",
+		"Lookups in the transposition table. The hit rate is scorer_search_table_hits_total over this.")
+	tableHitsTotal = registry.Counter("scorer_search_table_hits_total",
+		"Lookups in the transposition table that found a position searched before.")
+)
+
+// observeSearch records how a search of a route went.
+func observeSearch(route string, result lockitdown.SearchResult, elapsed time.Duration) {
+	searchDepth.Observe(float64(result.Depth), route)
+	if elapsed > 0 {
+		searchNodesPerSecond.Observe(float64(result.Nodes)/elapsed.Seconds(), route)
+	}
+	tableProbesTotal.Add(float64(result.TableProbes))
+	tableHitsTotal.Add(float64(result.TableHits))
+}
@@ -1,70 +1,500 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"net/http"
+	"os"
+	"os/signal"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
 
+	"github.com/rwsargent/boardbots-go/bot"
+	"github.com/rwsargent/boardbots-go/client"
 	"github.com/rwsargent/boardbots-go/lockitdown"
+	"github.com/rwsargent/boardbots-go/quoridor"
 )
 
 type (
 	ScoreRequest struct {
+		// GameType is lockitdown or quoridor, lockitdown if it's empty.
+		GameType string `json:"gameType"`
+		// GameState is in the transport format of the game type.
+		GameState json.RawMessage `json:"state"`
+		Strategy  string          `json:"strategy"`
+		// Player is counted from 1, 0 is the player to move.
+		Player int `json:"player"`
+	}
+
+	ScoreResponse struct {
+		Score int `json:"score"`
+	}
+
+	// BatchScoreResult is the result of one request of a batch, Error is
+	// set instead of Score if it failed.
+	BatchScoreResult struct {
+		Score int    `json:"score"`
+		Error string `json:"error,omitempty"`
+	}
+
+	// scorer scores the state of a game for a player, counted from 1.
+	// Returns an error if the state or strategy is invalid.
+	scorer func(state json.RawMessage, strategy string, player int) (int, error)
+
+	// BestMoveRequest asks for the best move of the player to move, in
+	// lockitdown games. The search is bounded by depth, time in
+	// milliseconds, or both.
+	BestMoveRequest struct {
 		GameType  string                    `json:"gameType"`
 		GameState lockitdown.TransportState `json:"state"`
 		Strategy  string                    `json:"strategy"`
 		Player    int                       `json:"player"`
+		Depth     int                       `json:"depth"`
+		TimeMs    int                       `json:"timeMs"`
 	}
 
-	ScoreResponse struct {
-		Score int `json:"score"`
+	BestMoveResponse struct {
+		// Move is null if the player has no moves.
+		Move  *client.MoveT  `json:"move"`
+		Score int            `json:"score"`
+		Depth int            `json:"depth"`
+		PV    []client.MoveT `json:"pv"`
+		Nodes int            `json:"nodes"`
+	}
+
+	// SearchInfo is the progress of a streamed search, after each completed
+	// depth.
+	SearchInfo struct {
+		Depth          int            `json:"depth"`
+		Score          int            `json:"score"`
+		PV             []client.MoveT `json:"pv"`
+		Nodes          int            `json:"nodes"`
+		NodesPerSecond int            `json:"nodesPerSecond"`
+		TimeMs         int64          `json:"timeMs"`
+	}
+
+	// AnalyzeRequest asks for the score of every move of the player to
+	// move, with the same budget as BestMoveRequest.
+	AnalyzeRequest BestMoveRequest
+
+	AnalyzeResponse struct {
+		Depth int `json:"depth"`
+		// Moves are sorted best to worst.
+		Moves []MoveAnalysis `json:"moves"`
+	}
+
+	MoveAnalysis struct {
+		Move  client.MoveT   `json:"move"`
+		Score int            `json:"score"`
+		PV    []client.MoveT `json:"pv"`
+		// State is the game after the move.
+		State *lockitdown.TransportState `json:"state"`
 	}
 )
 
-func main() {
+// scorers are the scorers of each game type.
+var scorers = map[string]scorer{
+	"lockitdown": scoreLockItDown,
+	"quoridor":   scoreQuoridor,
+}
+
+const (
+	defaultDepth = 3
+	maxDepth     = 12
+	maxTime      = 10 * time.Second
 
+	shutdownTimeout = maxTime + 5*time.Second
+
+	maxBatchSize = 1000
+)
+
+func main() {
 	port := flag.String("port", ":8888", "server port")
+	drain := flag.Duration("drain", 0, "Time to report not ready before shutting down, for load balancers to stop sending requests.")
 	flag.Parse()
 
-	http.HandleFunc("/api/score", score)
+	server := &http.Server{
+		Addr:              *port,
+		Handler:           newHandler(),
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       10 * time.Second,
+		// Searches, and streams of them, take up to maxTime.
+		WriteTimeout: maxTime + 10*time.Second,
+		IdleTimeout:  time.Minute,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+	failed := make(chan error, 1)
+	go func() {
+		failed <- server.ListenAndServe()
+	}()
+	atomic.StoreInt32(&ready, 1)
+	logEvent("listening", "addr", *port)
 
-	fmt.Printf("Now listening on port %s\n", *port)
-	http.ListenAndServe(*port, nil)
+	select {
+	case err := <-failed:
+		logEvent("server failed", "err", err)
+		os.Exit(1)
+	case <-ctx.Done():
+	}
+
+	if err := shutdown(server, *drain); err != nil {
+		logEvent("shutdown failed", "err", err)
+		return
+	}
+	logEvent("done")
+}
 
-	fmt.Println("done")
+// shutdown stops taking traffic, reporting not ready for the drain time,
+// then lets searches in progress finish.
+func shutdown(server *http.Server, drain time.Duration) error {
+	atomic.StoreInt32(&ready, 0)
+	logEvent("shutting down", "drain", drain)
+	time.Sleep(drain)
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	return server.Shutdown(ctx)
+}
+
+// newHandler routes the requests of the server, through the middleware.
+func newHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/score", score)
+	mux.HandleFunc("/api/score/batch", scoreBatch)
+	mux.HandleFunc("/api/bestmove", bestMove)
+	mux.HandleFunc("/api/bestmove/stream", streamBestMove)
+	mux.HandleFunc("/api/analyze", analyze)
+	mux.HandleFunc("/healthz", healthz)
+	mux.HandleFunc("/readyz", readyz)
+	mux.Handle("/metrics", registry)
+	return logRequests(mux, withCORS(limitBody(mux)))
 }
 
 func score(w http.ResponseWriter, req *http.Request) {
 	var scoreReqest ScoreRequest
-	err := json.NewDecoder(req.Body).Decode(&scoreReqest)
-	defer req.Body.Close()
+	if !decodeRequest(w, req, &scoreReqest) {
+		return
+	}
+
+	score, err := scoreRequest(scoreReqest)
+	if err != nil {
+		badRequest(w, req, invalidRequest, err)
+		return
+	}
+
+	logEvent("scored", "gameType", scoreReqest.GameType, "strategy", scoreReqest.Strategy, "player", scoreReqest.Player, "score", score)
+	writeJSON(w, ScoreResponse{
+		score,
+	})
+}
+
+// scoreBatch scores an array of requests, at most maxBatchSize, with a
+// worker for each CPU. Each request gets its own result, in order, so one
+// bad request doesn't fail the rest.
+func scoreBatch(w http.ResponseWriter, req *http.Request) {
+	var scoreRequests []ScoreRequest
+	if !decodeRequest(w, req, &scoreRequests) {
+		return
+	}
+	if len(scoreRequests) > maxBatchSize {
+		badRequest(w, req, invalidRequest, fmt.Errorf("batch has %d requests, at most %d are allowed", len(scoreRequests), maxBatchSize))
+		return
+	}
+
+	results := make([]BatchScoreResult, len(scoreRequests))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < runtime.GOMAXPROCS(0) && i < len(scoreRequests); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				score, err := scoreRequest(scoreRequests[job])
+				if err != nil {
+					results[job].Error = err.Error()
+					continue
+				}
+				results[job].Score = score
+			}
+		}()
+	}
+	for job := range scoreRequests {
+		if req.Context().Err() != nil {
+			break
+		}
+		jobs <- job
+	}
+	close(jobs)
+	wg.Wait()
+	if err := req.Context().Err(); err != nil {
+		logEvent("batch cancelled", "requests", len(scoreRequests), "err", err)
+		return
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Error != "" {
+			failed++
+		}
+	}
+	batchItemsTotal.Add(float64(len(results)-failed), "ok")
+	batchItemsTotal.Add(float64(failed), "error")
+	logEvent("scored batch", "requests", len(scoreRequests), "failed", failed)
+	writeJSON(w, results)
+}
+
+// scoreRequest scores the state with the scorer of its game type.
+func scoreRequest(scoreReqest ScoreRequest) (int, error) {
+	gameType := scoreReqest.GameType
+	if gameType == "" {
+		gameType = "lockitdown"
+	}
+	scorer, found := scorers[gameType]
+	if !found {
+		return 0, fmt.Errorf("unknown game type %q", scoreReqest.GameType)
+	}
+	return scorer(scoreReqest.GameState, scoreReqest.Strategy, scoreReqest.Player)
+}
 
-	fmt.Printf("Request:\n%+v\n", scoreReqest)
+func scoreLockItDown(b json.RawMessage, strategy string, player int) (int, error) {
+	evaluator, err := lockItDownEvaluator(strategy)
+	if err != nil {
+		return 0, err
+	}
+	var transport lockitdown.TransportState
+	if err := json.Unmarshal(b, &transport); err != nil {
+		return 0, err
+	}
+	if err := transport.Validate(); err != nil {
+		return 0, err
+	}
+	state := lockitdown.StateFromTransport(&transport)
+	position := state.PlayerTurn
+	if player != 0 {
+		position = lockitdown.PlayerPosition(player - 1)
+	}
+	if position < 0 || int(position) >= len(state.Players) {
+		return 0, fmt.Errorf("unknown player %d", player)
+	}
+	return evaluator(state, position), nil
+}
 
+func scoreQuoridor(b json.RawMessage, strategy string, player int) (int, error) {
+	if strategy == "" {
+		strategy = "default"
+	}
+	evaluator, found := quoridor.Evaluators[strategy]
+	if !found {
+		return 0, fmt.Errorf("unknown strategy %q", strategy)
+	}
+	var transport quoridor.TransportState
+	if err := json.Unmarshal(b, &transport); err != nil {
+		return 0, err
+	}
+	game, err := quoridor.StateFromTransport(&transport)
 	if err != nil {
-		fmt.Printf("error reading body, %v\n", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		return 0, err
+	}
+	position := game.CurrentTurn
+	if player != 0 {
+		position = quoridor.PlayerPosition(player - 1)
 	}
+	if _, found := game.Players[position]; !found {
+		return 0, fmt.Errorf("unknown player %d", player)
+	}
+	return evaluator(game, position), nil
+}
 
-	state := lockitdown.StateFromTransport(&scoreReqest.GameState)
-	var score int
-	switch scoreReqest.Strategy {
-	default:
-		score = lockitdown.ScoreGameState(state, lockitdown.PlayerPosition(scoreReqest.Player-1))
+// bestMove searches for the best move, for hints.
+func bestMove(w http.ResponseWriter, req *http.Request) {
+	var bestMoveRequest BestMoveRequest
+	state, opts, ok := searchRequest(w, req, &bestMoveRequest)
+	if !ok {
+		return
 	}
 
-	resp := ScoreResponse{
-		score,
+	start := time.Now()
+	result := lockitdown.Search(req.Context(), state, opts)
+	observeSearch("/api/bestmove", result, time.Since(start))
+	logEvent("best move", "depth", result.Depth, "nodes", result.Nodes, "score", result.Score)
+	writeJSON(w, bestMoveResponse(result))
+}
+
+// streamBestMove streams the progress of the search as server-sent events,
+// an info event after each depth, then a bestmove event. Without a budget,
+// the search goes on until the client disconnects or the longest search
+// time passes.
+func streamBestMove(w http.ResponseWriter, req *http.Request) {
+	var bestMoveRequest BestMoveRequest
+	state, opts, ok := searchRequest(w, req, &bestMoveRequest)
+	if !ok {
+		return
+	}
+	if bestMoveRequest.Depth == 0 {
+		opts.MaxDepth = maxDepth
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	start := time.Now()
+	opts.Progress = func(result lockitdown.SearchResult) {
+		elapsed := time.Since(start)
+		info := SearchInfo{
+			Depth:          result.Depth,
+			Score:          result.Score,
+			PV:             transportMoves(result.PV),
+			Nodes:          result.Nodes,
+			NodesPerSecond: int(float64(result.Nodes) / elapsed.Seconds()),
+			TimeMs:         elapsed.Milliseconds(),
+		}
+		writeEvent(w, "info", info)
+		flusher.Flush()
+	}
+	result := lockitdown.Search(req.Context(), state, opts)
+	observeSearch("/api/bestmove/stream", result, time.Since(start))
+	if req.Context().Err() != nil {
+		logEvent("search cancelled", "depth", result.Depth, "nodes", result.Nodes)
+		return
+	}
+	logEvent("best move", "depth", result.Depth, "nodes", result.Nodes, "score", result.Score)
+	writeEvent(w, "bestmove", bestMoveResponse(result))
+	flusher.Flush()
+}
+
+func bestMoveResponse(result lockitdown.SearchResult) BestMoveResponse {
+	resp := BestMoveResponse{
+		Score: result.Score,
+		Depth: result.Depth,
+		PV:    transportMoves(result.PV),
+		Nodes: result.Nodes,
+	}
+	if result.Move.Mover != nil {
+		move := bot.LockItDownMove(result.Move)
+		resp.Move = &move
+	}
+	return resp
+}
+
+// analyze scores every move, for reviewing games.
+func analyze(w http.ResponseWriter, req *http.Request) {
+	var analyzeRequest AnalyzeRequest
+	state, opts, ok := searchRequest(w, req, (*BestMoveRequest)(&analyzeRequest))
+	if !ok {
+		return
 	}
-	fmt.Printf("Response:\n%+v\n", resp)
 
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-	err = json.NewEncoder(w).Encode(resp)
+	analysis, depth := lockitdown.Analyze(req.Context(), state, opts)
+	searchDepth.Observe(float64(depth), "/api/analyze")
+	resp := AnalyzeResponse{
+		Depth: depth,
+		Moves: make([]MoveAnalysis, len(analysis)),
+	}
+	for i, move := range analysis {
+		after := lockitdown.StateFromTransport(&analyzeRequest.GameState)
+		after.Move(&move.Move)
+		resp.Moves[i] = MoveAnalysis{
+			Move:  bot.LockItDownMove(move.Move),
+			Score: move.Score,
+			PV:    transportMoves(move.PV),
+			State: lockitdown.ConvertToTransport(after),
+		}
+	}
+	logEvent("analyzed", "moves", len(analysis), "depth", depth)
+	writeJSON(w, resp)
+}
 
+// searchRequest decodes and validates a request to search a lockitdown
+// game. Returns false if the request was bad, and the error was written.
+func searchRequest(w http.ResponseWriter, req *http.Request, searchReq *BestMoveRequest) (*lockitdown.GameState, lockitdown.SearchOptions, bool) {
+	if !decodeRequest(w, req, searchReq) {
+		return nil, lockitdown.SearchOptions{}, false
+	}
+	opts, err := searchOptions(*searchReq)
 	if err != nil {
-		fmt.Printf("error writing response, %v", err)
+		badRequest(w, req, invalidRequest, err)
+		return nil, opts, false
+	}
+	if err := searchReq.GameState.Validate(); err != nil {
+		badRequest(w, req, invalidRequest, err)
+		return nil, opts, false
+	}
+	state := lockitdown.StateFromTransport(&searchReq.GameState)
+	if err := checkTurn(state, searchReq.Player); err != nil {
+		badRequest(w, req, invalidRequest, err)
+		return nil, opts, false
+	}
+	return state, opts, true
+}
+
+// checkTurn checks the player, counted from 1, is to move. Player 0 is
+// whoever is to move.
+func checkTurn(state *lockitdown.GameState, player int) error {
+	if player != 0 && player != int(state.PlayerTurn)+1 {
+		return fmt.Errorf("it is player %d's turn, not player %d's", state.PlayerTurn+1, player)
+	}
+	return nil
+}
+
+func transportMoves(moves []lockitdown.GameMove) []client.MoveT {
+	transport := make([]client.MoveT, len(moves))
+	for i, move := range moves {
+		transport[i] = bot.LockItDownMove(move)
+	}
+	return transport
+}
+
+func lockItDownEvaluator(strategy string) (lockitdown.Evaluator, error) {
+	if strategy == "" {
+		strategy = "default"
+	}
+	evaluator, found := lockitdown.Evaluators[strategy]
+	if !found {
+		return nil, fmt.Errorf("unknown strategy %q", strategy)
+	}
+	return evaluator, nil
+}
+
+// searchOptions validates the game type, budget and strategy of a request.
+// Only lockitdown games can be searched. Without a budget, the search is
+// defaultDepth deep. Without a time, searches still stop after maxTime.
+func searchOptions(req BestMoveRequest) (lockitdown.SearchOptions, error) {
+	if req.GameType != "" && req.GameType != "lockitdown" {
+		return lockitdown.SearchOptions{}, fmt.Errorf("can't search %q games", req.GameType)
+	}
+	evaluator, err := lockItDownEvaluator(req.Strategy)
+	if err != nil {
+		return lockitdown.SearchOptions{}, err
+	}
+
+	opts := lockitdown.SearchOptions{
+		Evaluator: evaluator,
+		MaxDepth:  req.Depth,
+		MoveTime:  time.Duration(req.TimeMs) * time.Millisecond,
+	}
+	switch {
+	case req.Depth < 0 || req.Depth > maxDepth:
+		return opts, fmt.Errorf("depth must be between 1 and %d", maxDepth)
+	case req.TimeMs < 0 || opts.MoveTime > maxTime:
+		return opts, fmt.Errorf("time must be between 1 and %d milliseconds", maxTime.Milliseconds())
+	case req.Depth == 0 && req.TimeMs == 0:
+		opts.MaxDepth = defaultDepth
+	case req.Depth == 0:
+		opts.MaxDepth = maxDepth
+	}
+	if opts.MoveTime == 0 {
+		opts.MoveTime = maxTime
 	}
+	return opts, nil
 }
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rwsargent/boardbots-go/bot"
+	"github.com/rwsargent/boardbots-go/client"
+	"github.com/rwsargent/boardbots-go/lockitdown"
+	"github.com/rwsargent/boardbots-go/quoridor"
+	"github.com/stretchr/testify/assert"
+)
+
+// decodeResponse decodes the JSON body of a successful response.
+func decodeResponse(t *testing.T, resp *http.Response, v any) {
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Nil(t, json.NewDecoder(resp.Body).Decode(v))
+}
+
+// playedGame is a new game after the moves, picking a different legal move
+// each time.
+func playedGame(t *testing.T, moves int) *lockitdown.GameState {
+	game := lockitdown.NewGame(lockitdown.DefaultGameDef)
+	for i := 0; i < moves; i++ {
+		possible := game.PossibleMoves(nil)
+		move := possible[(i*7)%len(possible)]
+		assert.Nil(t, game.Move(&move))
+	}
+	return game
+}
+
+func rawState(t *testing.T, game *lockitdown.GameState) json.RawMessage {
+	b, err := json.Marshal(lockitdown.ConvertToTransport(game))
+	assert.Nil(t, err)
+	return b
+}
+
+func TestScore(t *testing.T) {
+	server := newTestServer(t)
+	game := playedGame(t, 6)
+	state := rawState(t, game)
+
+	var resp ScoreResponse
+	decodeResponse(t, post(t, server, "/api/score", ScoreRequest{GameState: state, Player: 2}), &resp)
+	assert.Equal(t, lockitdown.Evaluators["default"](game, 1), resp.Score)
+
+	unknown := post(t, server, "/api/score", ScoreRequest{GameType: "chess", GameState: state})
+	assert.Equal(t, http.StatusBadRequest, unknown.StatusCode)
+}
+
+func TestScoreQuoridor(t *testing.T) {
+	server := newTestServer(t)
+	// The pawns are as far from their goals, player one has three more
+	// barriers left.
+	b, err := json.Marshal(quoridor.TransportState{
+		Players: []quoridor.TransportPlayer{
+			{Pawn: quoridor.Position{X: 8, Y: 16}, Barriers: 10},
+			{Pawn: quoridor.Position{X: 8, Y: 0}, Barriers: 7},
+		},
+	})
+	assert.Nil(t, err)
+
+	for player, score := range map[int]int{0: 3, 1: 3, 2: -3} {
+		var resp ScoreResponse
+		decodeResponse(t, post(t, server, "/api/score", ScoreRequest{GameType: "quoridor", GameState: b, Player: player}), &resp)
+		assert.Equal(t, score, resp.Score, "player %d", player)
+	}
+
+	// It isn't a lockitdown game.
+	resp := post(t, server, "/api/score", ScoreRequest{GameState: b})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestScoreBatch(t *testing.T) {
+	server := newTestServer(t)
+
+	var states []json.RawMessage
+	for moves := 0; moves < 12; moves += 3 {
+		states = append(states, rawState(t, playedGame(t, moves)))
+	}
+	// The bad requests are among good ones, and don't fail them.
+	requests := []ScoreRequest{
+		{GameState: states[0], Player: 1},
+		{GameState: states[1], Player: 2},
+		{GameState: states[1], Player: 1},
+		{GameState: states[2], Strategy: "nonsense"},
+		{GameType: "chess", GameState: states[2]},
+		{GameState: json.RawMessage(`{"players": []}`)},
+		{GameState: states[2], Player: 2},
+		{GameState: states[3], Player: 1},
+		{GameState: states[3], Player: 2},
+	}
+
+	var results []BatchScoreResult
+	decodeResponse(t, post(t, server, "/api/score/batch", requests), &results)
+	assert.Len(t, results, len(requests))
+
+	distinct := map[int]bool{}
+	for i, request := range requests {
+		score, err := scoreRequest(request)
+		if err != nil {
+			assert.Equal(t, BatchScoreResult{Error: err.Error()}, results[i], "request %d", i)
+			continue
+		}
+		assert.Equal(t, BatchScoreResult{Score: score}, results[i], "request %d", i)
+		distinct[score] = true
+	}
+	// The scores differ, so results out of order would be caught.
+	assert.Greater(t, len(distinct), 2)
+	for _, i := range []int{3, 4, 5} {
+		assert.NotEmpty(t, results[i].Error, "request %d", i)
+	}
+}
+
+func TestScoreBatchTooLarge(t *testing.T) {
+	server := newTestServer(t)
+	state := rawState(t, lockitdown.NewGame(lockitdown.DefaultGameDef))
+
+	requests := make([]ScoreRequest, maxBatchSize+1)
+	for i := range requests {
+		requests[i] = ScoreRequest{GameState: state}
+	}
+	resp := post(t, server, "/api/score/batch", requests)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	var results []BatchScoreResult
+	decodeResponse(t, post(t, server, "/api/score/batch", requests[:maxBatchSize]), &results)
+	assert.Len(t, results, maxBatchSize)
+}
+
+// playPV plays the moves of a principal variation on the game, which must
+// all be legal.
+func playPV(t *testing.T, game *lockitdown.GameState, pv []client.MoveT) {
+	for i, move := range pv {
+		gameMove := mustGameMove(t, move)
+		assert.Nil(t, game.Move(&gameMove), "move %d", i)
+	}
+}
+
+func mustGameMove(t *testing.T, move client.MoveT) lockitdown.GameMove {
+	gameMove, err := bot.LockItDownGameMove(move)
+	assert.Nil(t, err)
+	return gameMove
+}
+
+func TestBestMove(t *testing.T) {
+	server := newTestServer(t)
+	game := playedGame(t, 5)
+	state := lockitdown.ConvertToTransport(game)
+
+	var resp BestMoveResponse
+	decodeResponse(t, post(t, server, "/api/bestmove", BestMoveRequest{GameState: *state, Depth: 2}), &resp)
+	assert.Equal(t, 2, resp.Depth)
+	assert.NotNil(t, resp.Move)
+	assert.Greater(t, resp.Nodes, 0)
+	if assert.NotEmpty(t, resp.PV) {
+		assert.LessOrEqual(t, len(resp.PV), 2)
+		assert.Equal(t, mustGameMove(t, *resp.Move), mustGameMove(t, resp.PV[0]))
+		assert.Equal(t, int(game.PlayerTurn)+1, resp.PV[0].Player)
+	}
+	playPV(t, game, resp.PV)
+
+	// Without a budget, the search is defaultDepth deep.
+	decodeResponse(t, post(t, server, "/api/bestmove", BestMoveRequest{GameState: *state}), &resp)
+	assert.Equal(t, defaultDepth, resp.Depth)
+}
+
+func TestBestMoveWrongPlayer(t *testing.T) {
+	server := newTestServer(t)
+	game := playedGame(t, 5)
+	state := lockitdown.ConvertToTransport(game)
+	toMove := int(game.PlayerTurn) + 1
+	notToMove := 3 - toMove
+
+	resp := post(t, server, "/api/bestmove", BestMoveRequest{GameState: *state, Player: notToMove, Depth: 1})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	var bestMove BestMoveResponse
+	decodeResponse(t, post(t, server, "/api/bestmove", BestMoveRequest{GameState: *state, Player: toMove, Depth: 1}), &bestMove)
+	assert.Equal(t, toMove, bestMove.Move.Player)
+}
+
+func TestAnalyze(t *testing.T) {
+	server := newTestServer(t)
+	game := playedGame(t, 5)
+	state := lockitdown.ConvertToTransport(game)
+
+	var resp AnalyzeResponse
+	decodeResponse(t, post(t, server, "/api/analyze", AnalyzeRequest{GameState: *state, Depth: 1}), &resp)
+	assert.Equal(t, 1, resp.Depth)
+	assert.Len(t, resp.Moves, len(game.PossibleMoves(nil)))
+
+	for i, analysis := range resp.Moves {
+		if i > 0 {
+			assert.LessOrEqual(t, analysis.Score, resp.Moves[i-1].Score, "move %d is better than the one before", i)
+		}
+		after := lockitdown.StateFromTransport(state)
+		move := mustGameMove(t, analysis.Move)
+		assert.Nil(t, after.Move(&move), "move %d", i)
+		assert.Equal(t, lockitdown.ConvertToTransport(after), analysis.State, "move %d", i)
+	}
+	// The moves aren't all as good.
+	assert.Greater(t, resp.Moves[0].Score, resp.Moves[len(resp.Moves)-1].Score)
+}
+
+type event struct {
+	name string
+	data string
+}
+
+// readEvents reads server-sent events until the stream ends, or stop returns
+// true for the last one.
+func readEvents(t *testing.T, resp *http.Response, stop func(event) bool) []event {
+	var events []event
+	var current event
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(nil, maxBodyBytes)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			current.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			current.data = strings.TrimPrefix(line, "data: ")
+		case line == "":
+			events = append(events, current)
+			if stop(current) {
+				return events
+			}
+			current = event{}
+		}
+	}
+	assert.Nil(t, scanner.Err())
+	return events
+}
+
+func TestStreamBestMove(t *testing.T) {
+	server := newTestServer(t)
+	game := playedGame(t, 5)
+	state := lockitdown.ConvertToTransport(game)
+
+	resp := post(t, server, "/api/bestmove/stream", BestMoveRequest{GameState: *state, Depth: 3})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	events := readEvents(t, resp, func(event) bool { return false })
+
+	if !assert.Len(t, events, 4) {
+		return
+	}
+	for i, e := range events[:3] {
+		assert.Equal(t, "info", e.name)
+		var info SearchInfo
+		assert.Nil(t, json.Unmarshal([]byte(e.data), &info))
+		assert.Equal(t, i+1, info.Depth)
+		assert.NotEmpty(t, info.PV)
+		playPV(t, lockitdown.StateFromTransport(state), info.PV)
+	}
+	assert.Equal(t, "bestmove", events[3].name)
+	var bestMove BestMoveResponse
+	assert.Nil(t, json.Unmarshal([]byte(events[3].data), &bestMove))
+	assert.Equal(t, 3, bestMove.Depth)
+	assert.NotNil(t, bestMove.Move)
+}
+
+func TestStreamBestMoveDisconnect(t *testing.T) {
+	server := newTestServer(t)
+	state := lockitdown.ConvertToTransport(playedGame(t, 5))
+	searches := metricValue(t, `scorer_search_depth_count{route="/api/bestmove/stream"}`)
+
+	// Without a budget, the search would go on for maxTime.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	b, err := json.Marshal(BestMoveRequest{GameState: *state})
+	assert.Nil(t, err)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL+"/api/bestmove/stream", bytes.NewReader(b))
+	assert.Nil(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	events := readEvents(t, resp, func(e event) bool { return e.name == "info" })
+	assert.Equal(t, "info", events[len(events)-1].name)
+
+	start := time.Now()
+	cancel()
+	assert.Eventually(t, func() bool {
+		return metricValue(t, `scorer_search_depth_count{route="/api/bestmove/stream"}`) == searches+1
+	}, maxTime/2, 10*time.Millisecond, "the search wasn't cancelled")
+	t.Logf("search stopped %s after disconnecting", time.Since(start))
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// server.go has the plumbing shared by the handlers: request limits, CORS,
+// logging, and health checks.
+
+// maxBodyBytes limits the size of requests, the largest games are a few
+// kilobytes. Batches of maxBatchSize games get more.
+const (
+	maxBodyBytes      = 1 << 20
+	maxBatchBodyBytes = 16 << 20
+)
+
+// errBodyTooLarge is the error reading a request past its limit.
+var errBodyTooLarge = errors.New("request body too large")
+
+// ready is 1 while the server takes requests, and 0 once it's shutting down.
+var ready int32
+
+// limitedBody is a request body cut off at limit bytes, that tells going over
+// the limit apart from other errors.
+type limitedBody struct {
+	io.ReadCloser
+	read  int64
+	limit int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	if err != nil && err != io.EOF && b.read >= b.limit {
+		err = errBodyTooLarge
+	}
+	return n, err
+}
+
+// statusRecorder records the response, for logging.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
+
+// Flush lets handlers stream through the recorder.
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// logRequests logs a line for each request once it's served, and counts it
+// by the route of the mux, so unknown paths can't add metric series.
+func logRequests(mux *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, req)
+		duration := time.Since(start)
+
+		_, route := mux.Handler(req)
+		if route == "" {
+			route = "unknown"
+		}
+		requestsTotal.Inc(route, strconv.Itoa(recorder.status))
+		requestDuration.Observe(duration.Seconds(), route)
+		logEvent("request",
+			"method", req.Method,
+			"path", req.URL.Path,
+			"status", recorder.status,
+			"bytes", recorder.bytes,
+			"durationMs", duration.Milliseconds(),
+			"remote", req.RemoteAddr)
+	})
+}
+
+// withCORS lets the web frontend call the server from another origin.
+func withCORS(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		if req.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// limitBody rejects requests larger than their limit, up front if they say
+// how large they are, or once they've been read that far.
+func limitBody(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		limit := int64(maxBodyBytes)
+		if req.URL.Path == "/api/score/batch" {
+			limit = maxBatchBodyBytes
+		}
+		if req.ContentLength > limit {
+			tooLarge(w, req)
+			return
+		}
+		req.Body = &limitedBody{ReadCloser: http.MaxBytesReader(w, req.Body, limit), limit: limit}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// healthz reports the process is up.
+func healthz(w http.ResponseWriter, req *http.Request) {
+	fmt.Fprintln(w, "ok")
+}
+
+// readyz reports whether the server takes requests.
+func readyz(w http.ResponseWriter, req *http.Request) {
+	if atomic.LoadInt32(&ready) == 0 {
+		http.Error(w, "shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	fmt.Fprintln(w, "ok")
+}
+
+// decodeRequest decodes the JSON body of a POST. Returns false if it
+// couldn't, and the error was written.
+func decodeRequest(w http.ResponseWriter, req *http.Request, v any) bool {
+	defer req.Body.Close()
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		errorsTotal.Inc(methodNotAllowed)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return false
+	}
+	if err := json.NewDecoder(req.Body).Decode(v); err != nil {
+		if errors.Is(err, errBodyTooLarge) {
+			tooLarge(w, req)
+			return false
+		}
+		badRequest(w, req, decodeError, fmt.Errorf("error reading body, %w", err))
+		return false
+	}
+	return true
+}
+
+// badRequest writes the error, counted as the kind of error.
+func badRequest(w http.ResponseWriter, req *http.Request, kind string, err error) {
+	errorsTotal.Inc(kind)
+	logEvent("bad request", "path", req.URL.Path, "type", kind, "err", err)
+	http.Error(w, err.Error(), http.StatusBadRequest)
+}
+
+// tooLarge writes the error of a request over its limit.
+func tooLarge(w http.ResponseWriter, req *http.Request) {
+	errorsTotal.Inc(bodyTooLarge)
+	logEvent("request too large", "path", req.URL.Path, "contentLength", req.ContentLength)
+	http.Error(w, errBodyTooLarge.Error(), http.StatusRequestEntityTooLarge)
+}
+
+func writeJSON(w http.ResponseWriter, resp any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		errorsTotal.Inc(writeError)
+		logEvent("error writing response", "err", err)
+	}
+}
+
+func writeEvent(w http.ResponseWriter, event string, data any) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		errorsTotal.Inc(writeError)
+		logEvent("error writing event", "event", event, "err", err)
+		return
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, b)
+}
+
+// logEvent logs a message with key value pairs, in logfmt.
+func logEvent(msg string, keyvals ...any) {
+	var line strings.Builder
+	line.WriteString("msg=")
+	line.WriteString(logValue(msg))
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		fmt.Fprintf(&line, " %v=%s", keyvals[i], logValue(keyvals[i+1]))
+	}
+	log.Println(line.String())
+}
+
+// logValue formats a value, quoted if it has spaces or quotes.
+func logValue(v any) string {
+	s := fmt.Sprint(v)
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rwsargent/boardbots-go/lockitdown"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(newHandler())
+	t.Cleanup(server.Close)
+	return server
+}
+
+// post posts the body to the server, encoded as JSON unless it's a string.
+func post(t *testing.T, server *httptest.Server, path string, body any) *http.Response {
+	b, ok := body.(string)
+	if !ok {
+		encoded, err := json.Marshal(body)
+		assert.Nil(t, err)
+		b = string(encoded)
+	}
+	resp, err := http.Post(server.URL+path, "application/json", strings.NewReader(b))
+	assert.Nil(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func newTestGame() *lockitdown.TransportState {
+	return lockitdown.ConvertToTransport(lockitdown.NewGame(lockitdown.DefaultGameDef))
+}
+
+func metricsText(t *testing.T) string {
+	var out strings.Builder
+	_, err := registry.WriteTo(&out)
+	assert.Nil(t, err)
+	return out.String()
+}
+
+// metricValue is the value of a series of the metrics, or 0 if it hasn't
+// been recorded.
+func metricValue(t *testing.T, series string) float64 {
+	for _, line := range strings.Split(metricsText(t), "\n") {
+		if value := strings.TrimPrefix(line, series+" "); value != line {
+			f, err := strconv.ParseFloat(value, 64)
+			assert.Nil(t, err)
+			return f
+		}
+	}
+	return 0
+}
+
+func TestBodyLimit(t *testing.T) {
+	server := newTestServer(t)
+	oversized := `{"state": "` + strings.Repeat("a", maxBodyBytes) + `"}`
+
+	resp := post(t, server, "/api/score", oversized)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+
+	// Without a length, the body is sent chunked and cut off while it's read.
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/api/score", io.MultiReader(strings.NewReader(oversized)))
+	assert.Nil(t, err)
+	resp, err = http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+
+	// Batches get more.
+	batch := `[{"state": "` + strings.Repeat("a", maxBodyBytes) + `"}]`
+	resp = post(t, server, "/api/score/batch", batch)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestDecodeRequest(t *testing.T) {
+	server := newTestServer(t)
+
+	resp, err := http.Get(server.URL + "/api/score")
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	assert.Equal(t, http.MethodPost, resp.Header.Get("Allow"))
+
+	resp = post(t, server, "/api/bestmove", `{"state": `)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = post(t, server, "/api/score", `[]`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestInvalidState(t *testing.T) {
+	server := newTestServer(t)
+
+	tests := map[string]func(state *lockitdown.TransportState){
+		"no players":       func(state *lockitdown.TransportState) { state.Players = nil },
+		"turn":             func(state *lockitdown.TransportState) { state.PlayerTurn = 3 },
+		"robot off board":  func(state *lockitdown.TransportState) { addRobot(state, lockitdown.Pair{Q: 9, R: 0}, 1) },
+		"robot of nobody":  func(state *lockitdown.TransportState) { addRobot(state, lockitdown.Pair{Q: 1, R: 0}, 5) },
+		"too many robots":  func(state *lockitdown.TransportState) { state.Players[0].PlacedRobots = 100 },
+		"winner is nobody": func(state *lockitdown.TransportState) { state.Status = "7" },
+	}
+	for name, invalidate := range tests {
+		t.Run(name, func(t *testing.T) {
+			state := newTestGame()
+			invalidate(state)
+			assert.NotNil(t, state.Validate())
+
+			for _, path := range []string{"/api/score", "/api/bestmove", "/api/analyze"} {
+				resp := post(t, server, path, map[string]any{"state": state})
+				assert.Equal(t, http.StatusBadRequest, resp.StatusCode, path)
+			}
+		})
+	}
+}
+
+// addRobot adds a robot of the player, counted from 1, to the state.
+func addRobot(state *lockitdown.TransportState, position lockitdown.Pair, player int) {
+	state.Robots = append(state.Robots, lockitdown.TransportRobots{
+		Position: position,
+		Robot:    lockitdown.TransportRobot{Dir: lockitdown.Pair{Q: -1, R: 0}, Player: player},
+	})
+}
+
+func TestHealth(t *testing.T) {
+	server := httptest.NewServer(newHandler())
+	defer server.Close()
+	atomic.StoreInt32(&ready, 1)
+
+	for _, path := range []string{"/healthz", "/readyz"} {
+		resp, err := http.Get(server.URL + path)
+		assert.Nil(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode, path)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- shutdown(server.Config, 500*time.Millisecond)
+	}()
+	assert.Eventually(t, func() bool {
+		resp, err := http.Get(server.URL + "/readyz")
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusServiceUnavailable
+	}, 400*time.Millisecond, 10*time.Millisecond)
+
+	resp, err := http.Get(server.URL + "/healthz")
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Nil(t, <-done)
+}
+
+func TestRouteLabels(t *testing.T) {
+	server := newTestServer(t)
+
+	resp, err := http.Get(server.URL + "/api/score")
+	assert.Nil(t, err)
+	resp.Body.Close()
+	post(t, server, "/api/bestmove", "{")
+	resp, err = http.Get(server.URL + "/no/such/path")
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	// Requests are counted once they've been answered.
+	var text string
+	assert.Eventually(t, func() bool {
+		text = metricsText(t)
+		return strings.Contains(text, `route="unknown",status="404"`)
+	}, time.Second, 10*time.Millisecond)
+	assert.Contains(t, text, `scorer_requests_total{route="/api/score",status="405"}`)
+	assert.Contains(t, text, `scorer_requests_total{route="/api/bestmove",status="400"}`)
+	assert.Contains(t, text, `scorer_request_duration_seconds_count{route="/api/bestmove"}`)
+	assert.NotContains(t, text, "/no/such/path")
+}
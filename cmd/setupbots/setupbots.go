@@ -1,13 +1,16 @@
-// Setupbots authenticates two users and starts a lockitdown game.
-// Requires two usernames, one as host and the other as the opponent,
-// and a server address.
+// Setupbots authenticates the players and starts a lockitdown game.
+// Requires the username of the host, one opponent for each other player,
+// and a server address. The game can be configured with flags.
 //
-// $> setupbos -host=host-bot -oppo=oppo-bot -server=https://boardbots.dev
+// $> setupbos -host=host-bot -oppo=oppo-bot -server=https://boardbots.dev -radius=3 -robots=4
+// $> setupbos -host=host-bot -oppo=oppo-bot,third-bot -players=3
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"strings"
 
 	"github.com/rwsargent/boardbots-go/client"
 	"github.com/rwsargent/boardbots-go/lockitdown"
@@ -15,39 +18,75 @@ import (
 
 func main() {
 	hostName := flag.String("host", "host-bot", "Username of the host of the game.")
-	oppoName := flag.String("oppo", "oppo-bot", "Username of the opponent")
+	oppoNames := flag.String("oppo", "oppo-bot", "Usernames of the opponents, separated by commas.")
 	server := flag.String("server", "http://localhost:8080", "Url of boardbot server")
+	gameType := flag.String("game", "lockitdown", "Type of game to create, only lockitdown games can be set up.")
+	players := flag.Int("players", 2, "Players in the game, the host and the opponents.")
+	radius := flag.Int("radius", 4, "Arena radius of the board.")
+	robots := flag.Int("robots", 6, "Robots each player can place.")
+	movesPerTurn := flag.Int("moves", 3, "Moves each player makes per turn.")
+	winCondition := flag.String("win", "Elimination", "Win condition of the game.")
 
 	flag.Parse()
 
-	hostClient, err := client.NewBoardBotClient[lockitdown.TransportState](client.Credentials{Username: *hostName}, *server)
-
-	if err != nil {
+	if *gameType != "lockitdown" {
+		fmt.Printf("can't set up %q games\n", *gameType)
+		return
+	}
+	gameDef := lockitdown.GameDef{
+		Board:           lockitdown.Board{HexaBoard: lockitdown.BoardType{ArenaRadius: *radius}},
+		Players:         *players,
+		MovesPerTurn:    *movesPerTurn,
+		RobotsPerPlayer: *robots,
+		WinCondition:    *winCondition,
+	}
+	if err := gameDef.Validate(); err != nil {
 		fmt.Println(err)
 		return
 	}
+	opponents := strings.Split(*oppoNames, ",")
+	if len(opponents) != *players-1 {
+		fmt.Printf("%d players need %d opponents, have %d\n", *players, *players-1, len(opponents))
+		return
+	}
 
-	oppoClient, err := client.NewBoardBotClient[lockitdown.TransportState](client.Credentials{Username: *oppoName}, *server)
+	hostClient, err := client.NewBoardBotClient[lockitdown.TransportState](client.Credentials{Username: *hostName}, *server)
 
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
 
-	Must(hostClient.Authenticate())
+	oppoClients := make([]*client.BoardBotClient[lockitdown.TransportState], len(opponents))
+	for i, oppoName := range opponents {
+		oppoClients[i], err = client.NewBoardBotClient[lockitdown.TransportState](client.Credentials{Username: oppoName}, *server)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+
+	ctx := context.Background()
+	Must(hostClient.Authenticate(ctx))
 
-	lobby, err := hostClient.CreateLobby()
+	lobby, err := hostClient.CreateLobby(ctx, client.LobbyOptions{
+		GameType: *gameType,
+		Players:  *players,
+		Options:  gameDef,
+	})
 
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
 
-	Must(oppoClient.Authenticate())
-
-	Must(oppoClient.JoinLobby(lobby.Id.String()))
+	for _, oppoClient := range oppoClients {
+		Must(oppoClient.Authenticate(ctx))
+		_, err = oppoClient.JoinLobby(ctx, lobby.Id.String())
+		Must(err)
+	}
 
-	game, err := hostClient.StartGame(lobby.Id.String())
+	game, err := hostClient.StartGame(ctx, lobby.Id.String())
 	if err != nil {
 		fmt.Println(err)
 	}
@@ -0,0 +1,303 @@
+// Package engine plays lockitdown over a line based text protocol, the way
+// chess engines speak UCI, so GUIs and tournament managers can run it.
+//
+// The engine reads one command a line:
+//
+//	newgame [gamedef]            start a game of the JSON GameDef, or of lockitdown.DefaultGameDef
+//	position <json|record>       set the position to a JSON TransportState, or to the
+//	                             moves made since newgame, separated by spaces
+//	go [depth N] [movetime MS]   search the position, until stop without limits
+//	stop                         stop the search, it still replies with bestmove
+//	isready                      replies readyok
+//	quit                         stop the search and exit
+//
+// and replies with:
+//
+//	info depth D score S nodes N nps X time MS pv MOVE...
+//	bestmove MOVE, or bestmove none if there are no moves
+//	info string MESSAGE, for errors and unknown commands
+//
+// Moves are in the notation of lockitdown.FormatMove. An info line follows
+// every completed depth of the search.
+package engine
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rwsargent/boardbots-go/lockitdown"
+)
+
+type (
+	// Engine answers the commands of one client.
+	Engine struct {
+		// Evaluator scores positions, defaults to lockitdown.ScoreGameState.
+		Evaluator lockitdown.Evaluator
+
+		outLock sync.Mutex
+		out     io.Writer
+
+		gameDef lockitdown.GameDef
+		game    *lockitdown.GameState
+		// stop cancels the search in progress, finished is closed once it
+		// has its best move, and done once it has replied. All are nil
+		// while there's no search.
+		stop     context.CancelFunc
+		finished chan struct{}
+		done     chan struct{}
+	}
+
+	// searchLimits are the arguments of go.
+	searchLimits struct {
+		depth    int
+		moveTime time.Duration
+	}
+)
+
+// maxDepth is the depth of searches without a limit, they run until they're
+// stopped.
+const maxDepth = 64
+
+// maxLineBytes limits the length of commands, positions are a few kilobytes.
+const maxLineBytes = 1 << 20
+
+var errQuit = errors.New("quit")
+
+// New makes an engine replying to out, with a new game of
+// lockitdown.DefaultGameDef.
+func New(out io.Writer) *Engine {
+	return &Engine{
+		out:     out,
+		gameDef: lockitdown.DefaultGameDef,
+		game:    lockitdown.NewGame(lockitdown.DefaultGameDef),
+	}
+}
+
+// Run answers the commands read from in, until quit, the end of the input,
+// or the context is cancelled. A search in progress is stopped before it
+// returns.
+func (e *Engine) Run(ctx context.Context, in io.Reader) error {
+	defer e.stopSearch()
+
+	lines := make(chan string)
+	readErr := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(in)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxLineBytes)
+		for scanner.Scan() {
+			select {
+			case lines <- scanner.Text():
+			case <-ctx.Done():
+				return
+			}
+		}
+		readErr <- scanner.Err()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-readErr:
+			return err
+		case line := <-lines:
+			if err := e.Handle(line); err != nil {
+				if errors.Is(err, errQuit) {
+					return nil
+				}
+				e.printf("info string %s", err)
+			}
+		}
+	}
+}
+
+// Handle answers one command. Returns an error for bad commands.
+func (e *Engine) Handle(line string) error {
+	command, args, _ := strings.Cut(strings.TrimSpace(line), " ")
+	args = strings.TrimSpace(args)
+	switch command {
+	case "":
+		return nil
+	case "newgame":
+		return e.newGame(args)
+	case "position":
+		return e.position(args)
+	case "go":
+		return e.search(args)
+	case "stop":
+		e.stopSearch()
+		return nil
+	case "isready":
+		e.printf("readyok")
+		return nil
+	case "quit":
+		return errQuit
+	}
+	return fmt.Errorf("unknown command %q", command)
+}
+
+func (e *Engine) newGame(args string) error {
+	gameDef := lockitdown.DefaultGameDef
+	if args != "" {
+		if err := json.Unmarshal([]byte(args), &gameDef); err != nil {
+			return fmt.Errorf("invalid game definition: %w", err)
+		}
+	}
+	if err := gameDef.Validate(); err != nil {
+		return fmt.Errorf("invalid game definition: %w", err)
+	}
+	e.stopSearch()
+	e.gameDef = gameDef
+	e.game = lockitdown.NewGame(gameDef)
+	return nil
+}
+
+// position sets the game to a JSON transport state, or replays the moves of
+// a record from the start of the game.
+func (e *Engine) position(args string) error {
+	if strings.HasPrefix(args, "{") {
+		var transport lockitdown.TransportState
+		if err := json.Unmarshal([]byte(args), &transport); err != nil {
+			return fmt.Errorf("invalid position: %w", err)
+		}
+		if err := transport.Validate(); err != nil {
+			return fmt.Errorf("invalid position: %w", err)
+		}
+		e.stopSearch()
+		e.gameDef = transport.GameDef
+		e.game = lockitdown.StateFromTransport(&transport)
+		return nil
+	}
+
+	game := lockitdown.NewGame(e.gameDef)
+	for i, notation := range strings.Fields(args) {
+		if game.Winner >= 0 {
+			return fmt.Errorf("move %d, %s, is after the game is over", i+1, notation)
+		}
+		move, err := lockitdown.ParseMove(notation, game.PlayerTurn)
+		if err != nil {
+			return err
+		}
+		if err := game.Move(&move); err != nil && game.Winner < 0 {
+			return fmt.Errorf("move %d, %s, is illegal: %w", i+1, notation, err)
+		}
+	}
+	e.stopSearch()
+	e.game = game
+	return nil
+}
+
+// search starts searching the position, and replies once it's done.
+func (e *Engine) search(args string) error {
+	limits, err := parseLimits(args)
+	if err != nil {
+		return err
+	}
+	if e.searching() {
+		return errors.New("already searching, stop first")
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	finished, done := make(chan struct{}), make(chan struct{})
+	e.stop, e.finished, e.done = stop, finished, done
+	// Changing the position stops the search before it replaces the game,
+	// so the search has the game to itself.
+	game := e.game
+	start := time.Now()
+	opts := lockitdown.SearchOptions{
+		Evaluator: e.Evaluator,
+		MaxDepth:  limits.depth,
+		MoveTime:  limits.moveTime,
+		Progress: func(result lockitdown.SearchResult) {
+			e.info(result, time.Since(start))
+		},
+	}
+	go func() {
+		defer close(done)
+		result := lockitdown.Search(ctx, game, opts)
+		stop()
+		// Before the reply, so a go sent right after it isn't refused.
+		close(finished)
+		if result.Move.Mover == nil {
+			e.printf("bestmove none")
+			return
+		}
+		e.printf("bestmove %s", lockitdown.FormatMove(result.Move))
+	}()
+	return nil
+}
+
+// searching reports whether a search is still running, and forgets searches
+// that have finished.
+func (e *Engine) searching() bool {
+	if e.done == nil {
+		return false
+	}
+	select {
+	case <-e.finished:
+		e.stopSearch()
+		return false
+	default:
+		return true
+	}
+}
+
+// stopSearch stops the search in progress, and waits for its reply.
+func (e *Engine) stopSearch() {
+	if e.done == nil {
+		return
+	}
+	e.stop()
+	<-e.done
+	e.stop, e.finished, e.done = nil, nil, nil
+}
+
+func (e *Engine) info(result lockitdown.SearchResult, elapsed time.Duration) {
+	pv := make([]string, len(result.PV))
+	for i, move := range result.PV {
+		pv[i] = lockitdown.FormatMove(move)
+	}
+	nps := 0
+	if elapsed > 0 {
+		nps = int(float64(result.Nodes) / elapsed.Seconds())
+	}
+	e.printf("info depth %d score %d nodes %d nps %d time %d pv %s",
+		result.Depth, result.Score, result.Nodes, nps, elapsed.Milliseconds(), strings.Join(pv, " "))
+}
+
+func (e *Engine) printf(format string, args ...any) {
+	e.outLock.Lock()
+	defer e.outLock.Unlock()
+	fmt.Fprintf(e.out, format+"\n", args...)
+}
+
+func parseLimits(args string) (searchLimits, error) {
+	limits := searchLimits{depth: maxDepth}
+	fields := strings.Fields(args)
+	for i := 0; i < len(fields); i += 2 {
+		if i+1 == len(fields) {
+			return limits, fmt.Errorf("go %s needs a value", fields[i])
+		}
+		value, err := strconv.Atoi(fields[i+1])
+		if err != nil || value < 1 {
+			return limits, fmt.Errorf("go %s must be a positive number, is %q", fields[i], fields[i+1])
+		}
+		switch fields[i] {
+		case "depth":
+			limits.depth = value
+		case "movetime":
+			limits.moveTime = time.Duration(value) * time.Millisecond
+		default:
+			return limits, fmt.Errorf("unknown go limit %q", fields[i])
+		}
+	}
+	return limits, nil
+}
@@ -0,0 +1,168 @@
+package engine
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rwsargent/boardbots-go/lockitdown"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// session runs an engine, and reads its replies line by line.
+type session struct {
+	t       *testing.T
+	in      *io.PipeWriter
+	replies chan string
+	done    chan error
+}
+
+func startSession(t *testing.T) *session {
+	inReader, inWriter := io.Pipe()
+	outReader, outWriter := io.Pipe()
+	s := &session{
+		t:       t,
+		in:      inWriter,
+		replies: make(chan string, 100),
+		done:    make(chan error, 1),
+	}
+	go func() {
+		scanner := bufio.NewScanner(outReader)
+		for scanner.Scan() {
+			s.replies <- scanner.Text()
+		}
+		close(s.replies)
+	}()
+	go func() {
+		s.done <- New(outWriter).Run(context.Background(), inReader)
+		outWriter.Close()
+	}()
+	t.Cleanup(func() {
+		inWriter.Close()
+		outReader.Close()
+	})
+	return s
+}
+
+func (s *session) send(line string) {
+	_, err := fmt.Fprintln(s.in, line)
+	require.NoError(s.t, err)
+}
+
+// expect reads replies until one starts with prefix, and returns the
+// replies read.
+func (s *session) expect(prefix string) []string {
+	var lines []string
+	timeout := time.After(30 * time.Second)
+	for {
+		select {
+		case line, ok := <-s.replies:
+			require.True(s.t, ok, "engine stopped before replying %q, got %v", prefix, lines)
+			lines = append(lines, line)
+			if strings.HasPrefix(line, prefix) {
+				return lines
+			}
+		case <-timeout:
+			require.FailNow(s.t, "no reply", "waiting for %q, got %v", prefix, lines)
+		}
+	}
+}
+
+func TestSearchDepth(t *testing.T) {
+	s := startSession(t)
+	s.send("newgame")
+	s.send("position place:0,-5:SW place:5,-5:W")
+	s.send("go depth 2")
+	lines := s.expect("bestmove")
+
+	require.Len(t, lines, 3)
+	assert.True(t, strings.HasPrefix(lines[0], "info depth 1 score "), lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], "info depth 2 score "), lines[1])
+	assert.Contains(t, lines[1], " pv ")
+
+	_, notation, _ := strings.Cut(lines[2], " ")
+	_, err := lockitdown.ParseMove(notation, 0)
+	assert.NoError(t, err, lines[2])
+
+	s.send("quit")
+	assert.NoError(t, <-s.done)
+}
+
+func TestSearchTwice(t *testing.T) {
+	s := startSession(t)
+	s.send("go depth 1")
+	s.expect("bestmove")
+	// The first search has replied, a GUI doesn't stop it before the next.
+	s.send("go depth 1")
+	lines := s.expect("bestmove")
+	for _, line := range lines {
+		assert.False(t, strings.HasPrefix(line, "info string"), line)
+	}
+}
+
+func TestStop(t *testing.T) {
+	s := startSession(t)
+	s.send("go")
+	s.expect("info depth 1 ")
+	s.send("stop")
+	lines := s.expect("bestmove")
+	assert.NotEqual(t, "bestmove none", lines[len(lines)-1])
+
+	// The engine takes commands after it stopped.
+	s.send("isready")
+	s.expect("readyok")
+}
+
+func TestPositionJSON(t *testing.T) {
+	game := lockitdown.NewGame(lockitdown.DefaultGameDef)
+	move, err := lockitdown.ParseMove("place:0,-5:SW", 0)
+	require.NoError(t, err)
+	require.NoError(t, game.Move(&move))
+	state, err := json.Marshal(lockitdown.ConvertToTransport(game))
+	require.NoError(t, err)
+
+	s := startSession(t)
+	s.send("position " + string(state))
+	s.send("go depth 1")
+	lines := s.expect("bestmove")
+	assert.NotEqual(t, "bestmove none", lines[len(lines)-1])
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		command, reply string
+	}{
+		{"dance", `info string unknown command "dance"`},
+		{"go depth", "info string go depth needs a value"},
+		{"go depth -1", `info string go depth must be a positive number, is "-1"`},
+		{"go nodes 100", `info string unknown go limit "nodes"`},
+		{"newgame {", "info string invalid game definition"},
+		{"position place:9,9", "info string"},
+		{"position advance:0,-5", "info string move 1, advance:0,-5, is illegal"},
+		{"position {}", "info string invalid position"},
+	}
+	s := startSession(t)
+	for _, test := range tests {
+		t.Run(test.command, func(t *testing.T) {
+			s.send(test.command)
+			lines := s.expect("info string")
+			assert.True(t, strings.HasPrefix(lines[len(lines)-1], test.reply), lines)
+		})
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	limits, err := parseLimits("depth 3 movetime 250")
+	require.NoError(t, err)
+	assert.Equal(t, searchLimits{depth: 3, moveTime: 250 * time.Millisecond}, limits)
+
+	limits, err = parseLimits("")
+	require.NoError(t, err)
+	assert.Equal(t, searchLimits{depth: maxDepth}, limits)
+}
@@ -0,0 +1,170 @@
+// Package elo estimates rating differences from the results of a match
+// between two players.
+package elo
+
+import (
+	"fmt"
+	"math"
+)
+
+type (
+	// Result is a tally of game outcomes from one player's point of view.
+	Result struct {
+		Wins   int `json:"wins"`
+		Draws  int `json:"draws"`
+		Losses int `json:"losses"`
+	}
+)
+
+// z score for a 95% confidence interval.
+const confidence95 = 1.959964
+
+func (r Result) Games() int {
+	return r.Wins + r.Draws + r.Losses
+}
+
+// Score is the fraction of available points won, counting a draw as half.
+func (r Result) Score() float64 {
+	if r.Games() == 0 {
+		return 0.5
+	}
+	return (float64(r.Wins) + float64(r.Draws)/2) / float64(r.Games())
+}
+
+// Add combines two results.
+func (r Result) Add(that Result) Result {
+	return Result{
+		Wins:   r.Wins + that.Wins,
+		Draws:  r.Draws + that.Draws,
+		Losses: r.Losses + that.Losses,
+	}
+}
+
+// Flip returns the result from the opponent's point of view.
+func (r Result) Flip() Result {
+	return Result{
+		Wins:   r.Losses,
+		Draws:  r.Draws,
+		Losses: r.Wins,
+	}
+}
+
+// variance of a single game's score around the mean score.
+func (r Result) variance() float64 {
+	score := r.Score()
+	return (float64(r.Wins)*math.Pow(1-score, 2) +
+		float64(r.Draws)*math.Pow(0.5-score, 2) +
+		float64(r.Losses)*math.Pow(score, 2)) / float64(r.Games())
+}
+
+// Diff converts an expected score into an Elo difference. Scores of 0 and 1
+// return negative and positive infinity.
+func Diff(score float64) float64 {
+	if score <= 0 {
+		return math.Inf(-1)
+	}
+	if score >= 1 {
+		return math.Inf(1)
+	}
+	return 400 * math.Log10(score/(1-score))
+}
+
+// Estimate returns the Elo difference implied by the result, and the half
+// width of its 95% confidence interval.
+func (r Result) Estimate() (elo, margin float64) {
+	games := float64(r.Games())
+	if games == 0 {
+		return 0, math.Inf(1)
+	}
+	score := r.Score()
+	stdErr := math.Sqrt(r.variance() / games)
+
+	low := Diff(score - confidence95*stdErr)
+	high := Diff(score + confidence95*stdErr)
+	return Diff(score), (high - low) / 2
+}
+
+// SPRT is a sequential probability ratio test between the hypotheses that
+// the Elo difference is Elo0 (H0) or Elo1 (H1), with false positive rate
+// Alpha and false negative rate Beta.
+type SPRT struct {
+	Elo0, Elo1  float64
+	Alpha, Beta float64
+}
+
+// Decision is the state of a sequential test.
+type Decision int
+
+const (
+	Continue Decision = iota
+	AcceptH0
+	AcceptH1
+)
+
+func (d Decision) String() string {
+	switch d {
+	case AcceptH0:
+		return "H0 accepted"
+	case AcceptH1:
+		return "H1 accepted"
+	}
+	return "continue"
+}
+
+// Validate checks H1 is a larger Elo difference than H0, and the error
+// rates are probabilities that leave room for both decisions.
+func (s SPRT) Validate() error {
+	if !(s.Elo0 < s.Elo1) {
+		return fmt.Errorf("elo0 must be lower than elo1, have %g and %g", s.Elo0, s.Elo1)
+	}
+	if !(s.Alpha > 0 && s.Alpha < 1) {
+		return fmt.Errorf("alpha must be between 0 and 1, is %g", s.Alpha)
+	}
+	if !(s.Beta > 0 && s.Beta < 1) {
+		return fmt.Errorf("beta must be between 0 and 1, is %g", s.Beta)
+	}
+	if s.Alpha+s.Beta >= 1 {
+		return fmt.Errorf("alpha and beta must add up to less than 1, have %g and %g", s.Alpha, s.Beta)
+	}
+	return nil
+}
+
+// Bounds returns the log-likelihood ratios at which H0 and H1 are accepted.
+func (s SPRT) Bounds() (lower, upper float64) {
+	return math.Log(s.Beta / (1 - s.Alpha)), math.Log((1 - s.Beta) / s.Alpha)
+}
+
+// LLR approximates the log-likelihood ratio of H1 over H0 for the result,
+// treating the mean game score as normally distributed. Returns 0 until the
+// results have some variance.
+func (s SPRT) LLR(r Result) float64 {
+	games := float64(r.Games())
+	if games == 0 {
+		return 0
+	}
+	score := r.Score()
+	variance := r.variance()
+	if variance == 0 {
+		return 0
+	}
+	s0, s1 := expectedScore(s.Elo0), expectedScore(s.Elo1)
+	return games * (s1 - s0) * (2*score - s0 - s1) / (2 * variance)
+}
+
+// Test decides whether the result is enough to accept either hypothesis.
+func (s SPRT) Test(r Result) Decision {
+	llr := s.LLR(r)
+	lower, upper := s.Bounds()
+	if llr <= lower {
+		return AcceptH0
+	}
+	if llr >= upper {
+		return AcceptH1
+	}
+	return Continue
+}
+
+// expectedScore is the inverse of Diff.
+func expectedScore(elo float64) float64 {
+	return 1 / (1 + math.Pow(10, -elo/400))
+}
@@ -0,0 +1,98 @@
+package elo
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	testcases := []struct {
+		score float64
+		elo   float64
+	}{
+		{0.5, 0},
+		{0.75, 190.85},
+		{0.25, -190.85},
+		{0.9, 381.70},
+	}
+	for _, tc := range testcases {
+		assert.InDelta(t, tc.elo, Diff(tc.score), 0.01, "score %f", tc.score)
+	}
+	assert.True(t, math.IsInf(Diff(0), -1))
+	assert.True(t, math.IsInf(Diff(1), 1))
+}
+
+func TestEstimate(t *testing.T) {
+	elo, margin := Result{Wins: 30, Draws: 40, Losses: 30}.Estimate()
+	assert.InDelta(t, 0, elo, 0.001)
+	assert.InDelta(t, 53.2, margin, 0.1)
+
+	elo, margin = Result{Wins: 60, Draws: 20, Losses: 20}.Estimate()
+	assert.InDelta(t, 147.2, elo, 0.1)
+	assert.Greater(t, margin, 0.0)
+
+	_, margin = Result{}.Estimate()
+	assert.True(t, math.IsInf(margin, 1))
+}
+
+func TestFlip(t *testing.T) {
+	r := Result{Wins: 3, Draws: 2, Losses: 1}
+	assert.Equal(t, Result{Wins: 1, Draws: 2, Losses: 3}, r.Flip())
+	assert.Equal(t, Result{Wins: 4, Draws: 4, Losses: 4}, r.Add(r.Flip()))
+	assert.InDelta(t, 1-r.Score(), r.Flip().Score(), 1e-9)
+}
+
+func TestSPRTBounds(t *testing.T) {
+	lower, upper := SPRT{Alpha: 0.05, Beta: 0.05}.Bounds()
+	assert.InDelta(t, -2.944, lower, 0.001)
+	assert.InDelta(t, 2.944, upper, 0.001)
+}
+
+func TestSPRTValidate(t *testing.T) {
+	assert.Nil(t, SPRT{Elo0: 0, Elo1: 10, Alpha: 0.05, Beta: 0.05}.Validate())
+
+	testcases := []struct {
+		name string
+		sprt SPRT
+	}{
+		{"equal elos", SPRT{Elo0: 10, Elo1: 10, Alpha: 0.05, Beta: 0.05}},
+		{"elos reversed", SPRT{Elo0: 10, Elo1: 0, Alpha: 0.05, Beta: 0.05}},
+		{"elo not a number", SPRT{Elo0: math.NaN(), Elo1: 10, Alpha: 0.05, Beta: 0.05}},
+		{"no alpha", SPRT{Elo0: 0, Elo1: 10, Alpha: 0, Beta: 0.05}},
+		{"alpha of 1", SPRT{Elo0: 0, Elo1: 10, Alpha: 1, Beta: 0.05}},
+		{"negative beta", SPRT{Elo0: 0, Elo1: 10, Alpha: 0.05, Beta: -0.1}},
+		{"beta over 1", SPRT{Elo0: 0, Elo1: 10, Alpha: 0.05, Beta: 1.5}},
+		{"rates add up to 1", SPRT{Elo0: 0, Elo1: 10, Alpha: 0.5, Beta: 0.5}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.NotNil(t, tc.sprt.Validate())
+		})
+	}
+}
+
+func TestSPRT(t *testing.T) {
+	sprt := SPRT{Elo0: 0, Elo1: 10, Alpha: 0.05, Beta: 0.05}
+
+	testcases := []struct {
+		result   Result
+		decision Decision
+	}{
+		{Result{}, Continue},
+		{Result{Draws: 100}, Continue},
+		{Result{Wins: 10, Draws: 10, Losses: 10}, Continue},
+		{Result{Wins: 2000, Draws: 1000, Losses: 1500}, AcceptH1},
+		{Result{Wins: 1500, Draws: 1000, Losses: 2000}, AcceptH0},
+	}
+	for _, tc := range testcases {
+		assert.Equal(t, tc.decision, sprt.Test(tc.result), "result %+v, llr %f", tc.result, sprt.LLR(tc.result))
+	}
+}
+
+func TestExpectedScore(t *testing.T) {
+	for _, score := range []float64{0.1, 0.5, 0.75} {
+		assert.InDelta(t, score, expectedScore(Diff(score)), 1e-9)
+	}
+}
@@ -42,7 +42,10 @@ func Minimax(node Node, depth int) Node {
 			child.Move()
 			childsBest := Minimax(child, depth-1)
 			if childsBest.Score() > best.Score() {
-				best.Release()
+				// The first child starts out as the best, it's still in use.
+				if best != child {
+					best.Release()
+				}
 				best = child
 				best.SetScore(childsBest.Score())
 			}
@@ -58,7 +61,10 @@ func Minimax(node Node, depth int) Node {
 			child.Move()
 			childsBest := Minimax(child, depth-1)
 			if childsBest.Score() < best.Score() {
-				best.Release()
+				// The first child starts out as the best, it's still in use.
+				if best != child {
+					best.Release()
+				}
 				best = child
 				best.SetScore(childsBest.Score())
 			}
@@ -72,3 +78,64 @@ func Minimax(node Node, depth int) Node {
 	defer best.Release()
 	return best
 }
+
+// AlphaBeta finds the same best child as Minimax, but stops searching a node's children once one of them shows the
+// node won't be chosen. It prunes the most when Children returns the best moves first. The returned node is the
+// caller's to release.
+func AlphaBeta(node Node, depth int) Node {
+	best, score := alphaBeta(node, depth, math.MinInt, math.MaxInt)
+	if best == nil {
+		return node
+	}
+	best.SetScore(score)
+	return best
+}
+
+// alphaBeta returns the best child of the node, nil for leaves, and the node's score.
+func alphaBeta(node Node, depth, alpha, beta int) (Node, int) {
+	if depth == 0 {
+		node.Evaluate()
+		return nil, node.Score()
+	}
+	nodeBuffer := *(nodePool.Get().(*[]Node))
+	children := node.Children(nodeBuffer[:0])
+	defer nodePool.Put(&children)
+	if len(children) == 0 {
+		node.Evaluate()
+		return nil, node.Score()
+	}
+
+	maximize := node.ShouldMaximize()
+	var best Node
+	var bestScore int
+	for i, child := range children {
+		child.Move()
+		grandchild, score := alphaBeta(child, depth-1, alpha, beta)
+		child.Undo()
+		if grandchild != nil {
+			grandchild.Release()
+		}
+
+		if best == nil || (maximize && score > bestScore) || (!maximize && score < bestScore) {
+			if best != nil {
+				best.Release()
+			}
+			best, bestScore = child, score
+		} else {
+			child.Release()
+		}
+
+		if maximize && score > alpha {
+			alpha = score
+		} else if !maximize && score < beta {
+			beta = score
+		}
+		if alpha >= beta {
+			for _, skipped := range children[i+1:] {
+				skipped.Release()
+			}
+			break
+		}
+	}
+	return best, bestScore
+}
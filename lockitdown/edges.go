@@ -1,8 +1,14 @@
 package lockitdown
 
-import "sort"
+import (
+	"sort"
+	"sync"
+)
 
-var cache map[int][]Placement = make(map[int][]Placement)
+var (
+	cache     map[int][]Placement = make(map[int][]Placement)
+	cacheLock sync.RWMutex
+)
 
 type Placement struct {
 	position  Pair
@@ -18,7 +24,10 @@ func (a ByCorner) Less(i, j int) bool {
 }
 
 func edges(ringSize int) []Placement {
-	if cached, found := cache[ringSize]; found {
+	cacheLock.RLock()
+	cached, found := cache[ringSize]
+	cacheLock.RUnlock()
+	if found {
 		return cached
 	}
 
@@ -48,7 +57,9 @@ func edges(ringSize int) []Placement {
 		}
 	}
 	sort.Sort(ByCorner(edges))
+	cacheLock.Lock()
 	cache[ringSize] = edges
+	cacheLock.Unlock()
 	return edges
 }
 
@@ -2,6 +2,7 @@ package lockitdown
 
 import (
 	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -48,3 +49,18 @@ func TestDirection(t *testing.T) {
 		assert.LessOrEqual(t, next.Dist(), 3, "%s with direciton %s", edge.position.String(), edge.direction.String())
 	}
 }
+
+func TestEdgesConcurrently(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ringSize := 10; ringSize < 20; ringSize++ {
+				edges(ringSize)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 234, len(edges(10)))
+}
@@ -21,6 +21,12 @@ var attackAxes = map[Pair]func(Pair, Pair) bool{
 	},
 }
 
+// Evaluators maps strategy names, as used by the scorer server and local
+// tooling, to their Evaluator.
+var Evaluators = map[string]Evaluator{
+	"default": ScoreGameState,
+}
+
 func ScoreGameState(game *GameState, player PlayerPosition) int {
 	score := 0
 
@@ -147,6 +147,35 @@ func NewGame(gameDef GameDef) *GameState {
 	}
 }
 
+// DefaultGameDef is the two player game played on boardbots.dev.
+var DefaultGameDef = GameDef{
+	Board:           Board{HexaBoard: BoardType{ArenaRadius: 4}},
+	Players:         2,
+	MovesPerTurn:    3,
+	RobotsPerPlayer: 6,
+	WinCondition:    "Elimination",
+}
+
+// Validate reports whether a game can be played with the definition.
+func (def GameDef) Validate() error {
+	if def.Players < 2 || def.Players > 4 {
+		return fmt.Errorf("need 2 to 4 players, have %d", def.Players)
+	}
+	if def.Board.HexaBoard.ArenaRadius < 1 {
+		return fmt.Errorf("arena radius must be positive, is %d", def.Board.HexaBoard.ArenaRadius)
+	}
+	if def.MovesPerTurn < 1 {
+		return fmt.Errorf("moves per turn must be positive, is %d", def.MovesPerTurn)
+	}
+	if def.RobotsPerPlayer < 1 {
+		return fmt.Errorf("robots per player must be positive, is %d", def.RobotsPerPlayer)
+	}
+	if def.WinCondition != "Elimination" {
+		return fmt.Errorf("unsupported win condition %q", def.WinCondition)
+	}
+	return nil
+}
+
 // Only intended for Unit pairs
 func (p *Pair) Rotate(direction TurnDirection) {
 	s := p.S()
@@ -164,21 +193,25 @@ func (game *GameState) Move(move *GameMove) error {
 		return fmt.Errorf("wrong player, expected %d, was %d", game.PlayerTurn, move.Player)
 	}
 
-	game.saveState()
+	game.saveState(move)
 	err := move.Move(game)
 
 	if err != nil {
+		// Leave the game as it was before the failed move.
+		game.Undo(move)
 		return err
 	}
 
 	// Resolve move
 	if err = game.resolveMove(); err != nil {
+		// Ties aren't broken by the engine, leave the game as it was.
+		game.Undo(move)
 		return err
 	}
 
 	if game.MovesThisTurn == 0 {
 		game.PlayerTurn = PlayerPosition((int(game.PlayerTurn) + 1) % len(game.Players))
-		game.MovesThisTurn = 3
+		game.MovesThisTurn = game.GameDef.MovesPerTurn
 	}
 
 	if over, winner := game.checkGameOver(); over {
@@ -200,11 +233,24 @@ func (game *GameState) Undo(move *GameMove) error {
 
 	game.PlayerTurn = save.player
 	game.MovesThisTurn = save.movesThisTurn
+	game.Winner = save.winner
+	game.RequiresTieBreak = save.requiresTieBreak
 
 	game.saveStack = game.saveStack[:len(game.saveStack)-1]
 	return nil
 }
 
+// History returns the moves made that can be undone, oldest first.
+func (game *GameState) History() []GameMove {
+	history := make([]GameMove, 0, len(game.saveStack))
+	for _, save := range game.saveStack {
+		if move, ok := save.move.gameMove(); ok {
+			history = append(history, move)
+		}
+	}
+	return history
+}
+
 func (game *GameState) RobotAt(hex Pair) *Robot {
 	for i := 0; i < len(game.Robots); i++ {
 		robot := &game.Robots[i]
@@ -396,6 +442,8 @@ func (game *GameState) checkGameOver() (bool, int) {
 	return false, 0
 }
 
+// ToJson writes the game in the transport format of boardbots.dev, which
+// drops the undo history. Use SaveGame to keep the whole game.
 func (g *GameState) ToJson() (string, error) {
 	transportState := ConvertToTransport(g)
 	b, err := json.Marshal(transportState)
@@ -431,7 +479,7 @@ func inBounds(size int, position Pair) bool {
 	return position.Dist() <= size
 }
 
-func (state *GameState) saveState() {
+func (state *GameState) saveState(move *GameMove) {
 	save := SaveState{
 		players:       []Player{},
 		bots:          make([]Robot, len(state.Robots)),
@@ -444,6 +492,9 @@ func (state *GameState) saveState() {
 	copy(save.bots, state.Robots)
 	save.player = state.PlayerTurn
 	save.movesThisTurn = state.MovesThisTurn
+	save.winner = state.Winner
+	save.requiresTieBreak = state.RequiresTieBreak
+	save.move = saveMove(move)
 	state.saveStack = append(state.saveStack, save)
 }
 
@@ -2,6 +2,7 @@ package lockitdown
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/rand"
 	"testing"
@@ -9,16 +10,6 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-var TwoPlayerGameDef = GameDef{
-	Players: 2,
-	Board: Board{
-		HexaBoard: BoardType{4},
-	},
-	RobotsPerPlayer: 6,
-	WinCondition:    "Elimination",
-	MovesPerTurn:    3,
-}
-
 func TestNewGame(t *testing.T) {
 	game := NewGame(GameDef{
 		Players: 2,
@@ -33,8 +24,32 @@ func TestNewGame(t *testing.T) {
 		t.Error("Improperly initialized robots")
 	}
 }
+
+func TestValidateGameDef(t *testing.T) {
+	assert.Nil(t, DefaultGameDef.Validate())
+
+	testcases := []struct {
+		name   string
+		modify func(def *GameDef)
+	}{
+		{"one player", func(def *GameDef) { def.Players = 1 }},
+		{"five players", func(def *GameDef) { def.Players = 5 }},
+		{"no arena", func(def *GameDef) { def.Board.HexaBoard.ArenaRadius = 0 }},
+		{"no moves", func(def *GameDef) { def.MovesPerTurn = 0 }},
+		{"no robots", func(def *GameDef) { def.RobotsPerPlayer = 0 }},
+		{"unknown win condition", func(def *GameDef) { def.WinCondition = "Points" }},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			def := DefaultGameDef
+			tc.modify(&def)
+			assert.NotNil(t, def.Validate())
+		})
+	}
+}
+
 func TestMoves(t *testing.T) {
-	game := NewGame(TwoPlayerGameDef)
+	game := NewGame(DefaultGameDef)
 
 	tests := []struct {
 		move   Mover
@@ -142,9 +157,38 @@ func TestMoves(t *testing.T) {
 	assert.Equal(t, 3, game.Players[1].Points, "wrong number of player 2 points")
 }
 
-func TestGameOver(t *testing.T) {
-	gameState := GameState{
-		GameDef: TwoPlayerGameDef,
+func TestMovesPerTurn(t *testing.T) {
+	def := DefaultGameDef
+	def.MovesPerTurn = 2
+	game := NewGame(def)
+	assert.Equal(t, 2, game.MovesThisTurn)
+
+	assert.NoError(t, game.Move(NewMove(&PlaceRobot{Robot: Pair{0, 5}, Direction: Pair{0, -1}}, 0)))
+	assert.NoError(t, game.Move(NewMove(&PlaceRobot{Robot: Pair{5, 0}, Direction: Pair{-1, 0}}, 1)))
+	assert.NoError(t, game.Move(NewMove(&AdvanceRobot{Robot: Pair{0, 5}}, 0)))
+	assert.EqualError(t, game.Move(NewMove(&PlaceRobot{Robot: Pair{-5, 4}, Direction: Pair{1, 0}}, 0)),
+		"can only place a robot on your first action of the turn")
+	assert.NoError(t, game.Move(NewMove(&TurnRobot{Robot: Pair{0, 4}, Direction: Right}, 0)))
+
+	// Two actions end the turn.
+	assert.Equal(t, PlayerPosition(1), game.PlayerTurn)
+	assert.Equal(t, 2, game.MovesThisTurn)
+
+	placements := 0
+	it := NewMoveIterator(game)
+	for it.Next() {
+		if _, ok := it.Get().Mover.(*PlaceRobot); ok {
+			placements++
+		}
+	}
+	assert.NotZero(t, placements, "no placements on the first action of the turn")
+}
+
+// gameOverState is a game player 2 wins by turning the robot at -4,4 right,
+// once player 1 has advanced their robots.
+func gameOverState() *GameState {
+	return &GameState{
+		GameDef: DefaultGameDef,
 		Players: []*Player{
 			{
 				Points:       0,
@@ -204,6 +248,19 @@ func TestGameOver(t *testing.T) {
 		RequiresTieBreak: false,
 		Winner:           -1,
 	}
+}
+
+// winningPosition returns a game and the move that wins it.
+func winningPosition(t *testing.T) (*GameState, GameMove) {
+	game := gameOverState()
+	for _, robot := range []Pair{{4, -4}, {5, -5}, {0, 5}} {
+		assert.Nil(t, game.Move(NewMove(&AdvanceRobot{Robot: robot}, 0)))
+	}
+	return game, *NewMove(&TurnRobot{Robot: Pair{-4, 4}, Direction: Right}, 1)
+}
+
+func TestGameOver(t *testing.T) {
+	gameState := gameOverState()
 
 	testcases := []struct {
 		move   Mover
@@ -291,7 +348,7 @@ func TestInBounds(t *testing.T) {
 
 func TestPossibleMoves(t *testing.T) {
 
-	game := NewGame(TwoPlayerGameDef)
+	game := NewGame(DefaultGameDef)
 	initMoves := []*GameMove{
 		NewMove(&PlaceRobot{
 			Robot:     Pair{-5, 0},
@@ -357,7 +414,7 @@ func TestPossibleMoves(t *testing.T) {
 
 func TestFakeMinimaxStressTest(t *testing.T) {
 
-	game := NewGame(TwoPlayerGameDef)
+	game := NewGame(DefaultGameDef)
 
 	var recur func(*GameState, int)
 	recur = func(game *GameState, depth int) {
@@ -473,7 +530,7 @@ func TestPossibleMovesFromState(t *testing.T) {
 
 func BenchmarkPossibleMoves(b *testing.B) {
 	b.StopTimer()
-	game := NewGame(TwoPlayerGameDef)
+	game := NewGame(DefaultGameDef)
 
 	game.Robots = []Robot{
 		{
@@ -710,7 +767,7 @@ func TestFromState(t *testing.T) {
 }
 
 func TestEnterNoTieBreak(t *testing.T) {
-	game := NewGame(TwoPlayerGameDef)
+	game := NewGame(DefaultGameDef)
 	game.Robots = []Robot{
 		{
 			Position:      Pair{-4, 4},
@@ -754,7 +811,7 @@ func TestEnterNoTieBreak(t *testing.T) {
 }
 
 func TestTurnLocksDownBot(t *testing.T) {
-	game := NewGame(TwoPlayerGameDef)
+	game := NewGame(DefaultGameDef)
 	game.Robots = []Robot{
 		{
 			Position:      Pair{-4, 4},
@@ -806,7 +863,7 @@ func TestTurnLocksDownBot(t *testing.T) {
 }
 
 func TestTargeted(t *testing.T) {
-	game := NewGame(TwoPlayerGameDef)
+	game := NewGame(DefaultGameDef)
 	game.Robots = []Robot{
 		{
 			Position:      Pair{0, 0},
@@ -983,3 +1040,59 @@ func gameFromJson(jsonState string) *GameState {
 
 	return StateFromTransport(&tGame)
 }
+
+// tieBreakPosition plays random moves until one of the possible moves needs
+// a tie break, and returns the game and that move.
+func tieBreakPosition(t *testing.T) (*GameState, GameMove) {
+	game := NewGame(DefaultGameDef)
+	rng := rand.New(rand.NewSource(11))
+	for ply := 0; ply < 300; ply++ {
+		moves := game.PossibleMoves(nil)
+		for i := range moves {
+			move := moves[i]
+			err := game.Move(&move)
+			var tieBreak TieBreak
+			if errors.As(err, &tieBreak) {
+				return game, moves[i]
+			}
+			if err == nil || game.Winner >= 0 {
+				game.Undo(&move)
+			}
+		}
+		if len(moves) == 0 || game.Move(&moves[rng.Intn(len(moves))]) != nil {
+			break
+		}
+	}
+	t.Fatal("no position needing a tie break")
+	return nil, GameMove{}
+}
+
+func TestTieBreakMoveIsTakenBack(t *testing.T) {
+	game, move := tieBreakPosition(t)
+	before, err := SaveGame(game)
+	assert.Nil(t, err)
+
+	err = game.Move(&move)
+	var tieBreak TieBreak
+	assert.ErrorAs(t, err, &tieBreak)
+	assert.False(t, game.RequiresTieBreak)
+	after, err := SaveGame(game)
+	assert.Nil(t, err)
+	assert.JSONEq(t, string(before), string(after))
+}
+
+func TestUndoWinningMove(t *testing.T) {
+	game, move := winningPosition(t)
+	before, err := SaveGame(game)
+	assert.Nil(t, err)
+
+	err = game.Move(&move)
+	assert.EqualError(t, err, fmt.Sprintf("winner is %d", game.Winner+1))
+	assert.GreaterOrEqual(t, game.Winner, 0)
+
+	assert.Nil(t, game.Undo(&move))
+	assert.Equal(t, -1, game.Winner)
+	after, err := SaveGame(game)
+	assert.Nil(t, err)
+	assert.JSONEq(t, string(before), string(after))
+}
@@ -2,6 +2,7 @@ package lockitdown
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"sync"
@@ -18,6 +19,10 @@ type (
 		Searcher     PlayerPosition
 		Evaluator    Evaluator
 		MinimaxValue int
+
+		// moved is false if the game didn't take the move, and there's
+		// nothing to undo.
+		moved bool
 	}
 )
 
@@ -54,14 +59,22 @@ func (n *MinimaxNode) ShouldMaximize() bool {
 
 func (n *MinimaxNode) Move() {
 	err := n.GameState.Move(&n.GameMove)
-	if err != nil {
+	// Winning moves report the winner as an error, the search treats them as
+	// terminal nodes instead. Moves needing a tie break are taken back by the
+	// game, the search skips them.
+	n.moved = err == nil || n.GameState.Winner >= 0
+	var tieBreak TieBreak
+	if !n.moved && !errors.As(err, &tieBreak) {
 		json, _ := n.GameState.ToJson()
 		panic(fmt.Errorf("%s.\n\n%s", err, json))
 	}
 }
 
 func (n *MinimaxNode) Undo() {
-	n.GameState.Undo(&n.GameMove)
+	if n.moved {
+		n.GameState.Undo(&n.GameMove)
+		n.moved = false
+	}
 }
 
 func (n *MinimaxNode) Score() int {
@@ -108,6 +121,10 @@ func MinimaxWithIterator(node *MinimaxNode, depth int) MinimaxNode {
 		}
 
 		child.Move()
+		if !child.moved {
+			ReleaseMover(child.GameMove.Mover)
+			continue
+		}
 		childsBest := MinimaxWithIterator(&child, depth-1)
 		if comparator(childsBest.Score(), best.Score()) {
 			best = child
@@ -157,6 +174,10 @@ func alphaBeta(ctx context.Context, node *MinimaxNode, depth, alpha, beta int) M
 			}
 
 			child.Move()
+			if !child.moved {
+				ReleaseMover(child.GameMove.Mover)
+				continue
+			}
 			childsBest := alphaBeta(ctx, &child, depth-1, alpha, beta)
 			child.Undo()
 
@@ -183,6 +204,10 @@ func alphaBeta(ctx context.Context, node *MinimaxNode, depth, alpha, beta int) M
 			}
 
 			child.Move()
+			if !child.moved {
+				ReleaseMover(child.GameMove.Mover)
+				continue
+			}
 			childsBest := alphaBeta(ctx, &child, depth-1, alpha, beta)
 			child.Undo()
 
@@ -11,7 +11,7 @@ import (
 )
 
 func TestDepthOfOne(t *testing.T) {
-	game := NewGame(TwoPlayerGameDef)
+	game := NewGame(DefaultGameDef)
 
 	root := MinimaxNode{
 		GameState: game,
@@ -26,7 +26,7 @@ func TestDepthOfOne(t *testing.T) {
 }
 
 func TestDepthOfTwo(t *testing.T) {
-	game := NewGame(TwoPlayerGameDef)
+	game := NewGame(DefaultGameDef)
 
 	root := MinimaxNode{
 		GameState: game,
@@ -41,7 +41,7 @@ func TestDepthOfTwo(t *testing.T) {
 }
 
 func TestDepthOf3(t *testing.T) {
-	game := NewGame(TwoPlayerGameDef)
+	game := NewGame(DefaultGameDef)
 	originalJson, _ := game.ToJson()
 	root := MinimaxNode{
 		GameState: game,
@@ -59,7 +59,7 @@ func TestDepthOf3(t *testing.T) {
 
 func BenchmarkMinimax3(b *testing.B) {
 
-	game := NewGame(TwoPlayerGameDef)
+	game := NewGame(DefaultGameDef)
 	root := MinimaxNode{
 		GameState: game,
 		GameMove:  GameMove{},
@@ -74,7 +74,7 @@ func BenchmarkMinimax3(b *testing.B) {
 }
 
 func BenchmarkMinimaxWithIterator(b *testing.B) {
-	game := NewGame(TwoPlayerGameDef)
+	game := NewGame(DefaultGameDef)
 	root := MinimaxNode{
 		GameState: game,
 		GameMove:  GameMove{},
@@ -88,7 +88,7 @@ func BenchmarkMinimaxWithIterator(b *testing.B) {
 }
 
 func BenchmarkAlphaBetaVariousDepths(b *testing.B) {
-	game := NewGame(TwoPlayerGameDef)
+	game := NewGame(DefaultGameDef)
 	root := MinimaxNode{
 		GameState: game,
 		GameMove:  GameMove{},
@@ -105,3 +105,37 @@ func BenchmarkAlphaBetaVariousDepths(b *testing.B) {
 		})
 	}
 }
+
+func TestAlphaBetaSkipsTieBreaks(t *testing.T) {
+	game, _ := tieBreakPosition(t)
+	before, _ := SaveGame(game)
+	root := MinimaxNode{
+		GameState: game,
+		Searcher:  game.PlayerTurn,
+		Evaluator: ScoreGameState,
+	}
+
+	best := AlphaBeta(context.Background(), &root, 2)
+	assert.NotNil(t, best.GameMove.Mover)
+	after, _ := SaveGame(game)
+	assert.JSONEq(t, string(before), string(after))
+
+	MinimaxWithIterator(&root, 1)
+	after, _ = SaveGame(game)
+	assert.JSONEq(t, string(before), string(after))
+}
+
+func TestMinimaxNodeWinningMove(t *testing.T) {
+	game, move := winningPosition(t)
+	node := MinimaxNode{
+		GameState: game,
+		GameMove:  move,
+		Searcher:  game.PlayerTurn,
+		Evaluator: ScoreGameState,
+	}
+
+	assert.NotPanics(t, node.Move)
+	assert.Equal(t, 1, game.Winner)
+	node.Undo()
+	assert.Equal(t, -1, game.Winner)
+}
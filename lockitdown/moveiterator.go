@@ -51,6 +51,11 @@ func (it *MoveIterator) Get() *GameMove {
 }
 
 func (it *MoveIterator) Next() bool {
+	// No moves once the game has been decided.
+	if it.game.Winner >= 0 {
+		it.currentMove = nil
+		return false
+	}
 	it.findNext()
 	return it.currentMove != nil
 }
@@ -114,7 +119,8 @@ func (it *MoveIterator) findNext() {
 		}
 	}
 
-	if it.game.MovesThisTurn == 3 && it.game.playerBotsInCorridor() < 2 {
+	if it.game.MovesThisTurn == it.game.GameDef.MovesPerTurn && it.game.playerBotsInCorridor() < 2 &&
+		it.game.Players[it.game.PlayerTurn].PlacedRobots < it.game.GameDef.RobotsPerPlayer {
 		edges := edges(it.game.GameDef.Board.HexaBoard.ArenaRadius + 1)
 		for it.edgeIndex < len(edges) {
 			edge := edges[it.edgeIndex]
@@ -11,7 +11,7 @@ import (
 
 func TestIteratorThirdPly(t *testing.T) {
 
-	gameState := NewGame(TwoPlayerGameDef)
+	gameState := NewGame(DefaultGameDef)
 
 	gameState.Robots = []Robot{
 		{
@@ -59,7 +59,7 @@ func TestIteratorThirdPly(t *testing.T) {
 
 func TestNewGameIterator(t *testing.T) {
 
-	game := NewGame(TwoPlayerGameDef)
+	game := NewGame(DefaultGameDef)
 
 	it := NewMoveIterator(game)
 
@@ -73,7 +73,7 @@ func TestNewGameIterator(t *testing.T) {
 }
 
 func TestFullMoveIterator(t *testing.T) {
-	game := NewGame(TwoPlayerGameDef)
+	game := NewGame(DefaultGameDef)
 	it := NewMoveIterator(game)
 
 	game.Robots = []Robot{
@@ -319,3 +319,21 @@ func TestWrongMoveFromState(t *testing.T) {
 	err := state.Move(&move.GameMove)
 	assert.Nil(t, err)
 }
+
+func TestIteratorNoRobotsLeft(t *testing.T) {
+	game := NewGame(DefaultGameDef)
+	assert.True(t, NewMoveIterator(game).Next())
+
+	game.Players[0].PlacedRobots = DefaultGameDef.RobotsPerPlayer
+	assert.False(t, NewMoveIterator(game).Next(), "placements without robots left")
+}
+
+func TestIteratorAfterWin(t *testing.T) {
+	game, move := winningPosition(t)
+	assert.True(t, NewMoveIterator(game).Next())
+
+	game.Move(&move)
+	it := NewMoveIterator(game)
+	assert.False(t, it.Next(), "moves after the game was won")
+	assert.Nil(t, it.Get())
+}
@@ -101,12 +101,15 @@ func (m AdvanceRobot) String() string {
 }
 
 func (m *PlaceRobot) Move(game *GameState, player PlayerPosition) error {
-	if game.MovesThisTurn != 3 {
+	if game.MovesThisTurn != game.GameDef.MovesPerTurn {
 		return errors.New("can only place a robot on your first action of the turn")
 	}
 	if !game.isCorridor(m.Robot) {
 		return errors.New("must place robot in corridor")
 	}
+	if game.Players[player].PlacedRobots >= game.GameDef.RobotsPerPlayer {
+		return errors.New("no robots left to place")
+	}
 
 	robotsInCorridor := 0
 	for _, robot := range game.Robots {
@@ -39,7 +39,7 @@ func TestTurn(t *testing.T) {
 }
 
 func TestAdvance(t *testing.T) {
-	state := NewGame(TwoPlayerGameDef)
+	state := NewGame(DefaultGameDef)
 	state.Robots = []Robot{
 		{
 			Position:      Pair{2, 3},
@@ -66,7 +66,7 @@ func TestAdvance(t *testing.T) {
 }
 
 func TestAdvanceBlocksLockdown(t *testing.T) {
-	state := NewGame(TwoPlayerGameDef)
+	state := NewGame(DefaultGameDef)
 	state.Robots = []Robot{
 		{
 			Position:      Pair{4, 0},
@@ -114,7 +114,7 @@ func TestAdvanceBlocksLockdown(t *testing.T) {
 }
 
 func TestAdvanceRemovesBot(t *testing.T) {
-	state := NewGame(TwoPlayerGameDef)
+	state := NewGame(DefaultGameDef)
 	state.Robots = []Robot{
 		{
 			Position:      Pair{4, 0},
@@ -166,7 +166,7 @@ func TestAdvanceRemovesBot(t *testing.T) {
 }
 
 func TestTurnLockUnlock(t *testing.T) {
-	state := NewGame(TwoPlayerGameDef)
+	state := NewGame(DefaultGameDef)
 
 	state.Robots = []Robot{
 		{
@@ -248,7 +248,7 @@ func TestTurnLockUnlock(t *testing.T) {
 }
 
 func TestRemovedToLock(t *testing.T) {
-	game := NewGame(TwoPlayerGameDef)
+	game := NewGame(DefaultGameDef)
 	game.Robots = []Robot{
 		{
 			Position:      Pair{0, 4},
@@ -283,7 +283,7 @@ func TestRemovedToLock(t *testing.T) {
 
 func TestPlacedRobots(t *testing.T) {
 
-	game := NewGame(TwoPlayerGameDef)
+	game := NewGame(DefaultGameDef)
 
 	m1 := NewMove(&PlaceRobot{
 		Robot:     Pair{0, 5},
@@ -400,3 +400,12 @@ func TestCantMoveForwardWithIterator(t *testing.T) {
 	}
 
 }
+
+func TestPlaceRobotNoneLeft(t *testing.T) {
+	game := NewGame(DefaultGameDef)
+	game.Players[0].PlacedRobots = DefaultGameDef.RobotsPerPlayer
+
+	err := game.Move(NewMove(&PlaceRobot{Robot: Pair{0, 5}, Direction: NW}, 0))
+	assert.EqualError(t, err, "no robots left to place")
+	assert.Empty(t, game.Robots)
+}
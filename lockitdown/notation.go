@@ -0,0 +1,91 @@
+package lockitdown
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// notation.go writes moves as short words without spaces, for text
+// protocols:
+//
+//	place:q,r:DIR    place a robot on the corridor, facing NW, NE, E, SE, SW or W
+//	advance:q,r      advance the robot
+//	turn:q,r:left    turn the robot left, or right
+
+// FormatMove writes the move in the notation ParseMove reads.
+func FormatMove(move GameMove) string {
+	switch m := move.Mover.(type) {
+	case *PlaceRobot:
+		return fmt.Sprintf("place:%d,%d:%s", m.Robot.Q, m.Robot.R, directionNames[m.Direction])
+	case *AdvanceRobot:
+		return fmt.Sprintf("advance:%d,%d", m.Robot.Q, m.Robot.R)
+	case *TurnRobot:
+		turn := "left"
+		if m.Direction == Right {
+			turn = "right"
+		}
+		return fmt.Sprintf("turn:%d,%d:%s", m.Robot.Q, m.Robot.R, turn)
+	}
+	return fmt.Sprintf("%v", move.Mover)
+}
+
+// ParseMove reads a move made by player, in the notation of FormatMove.
+func ParseMove(s string, player PlayerPosition) (GameMove, error) {
+	move := GameMove{Player: player}
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 {
+		return move, fmt.Errorf("move %q isn't kind:q,r", s)
+	}
+	position, err := parsePair(parts[1])
+	if err != nil {
+		return move, fmt.Errorf("move %q: %w", s, err)
+	}
+
+	switch {
+	case parts[0] == "place" && len(parts) == 3:
+		direction, found := directionsByName[strings.ToUpper(parts[2])]
+		if !found {
+			return move, fmt.Errorf("move %q: unknown direction %q", s, parts[2])
+		}
+		move.Mover = &PlaceRobot{Robot: position, Direction: direction}
+	case parts[0] == "advance" && len(parts) == 2:
+		move.Mover = &AdvanceRobot{Robot: position}
+	case parts[0] == "turn" && len(parts) == 3:
+		switch strings.ToLower(parts[2]) {
+		case "left":
+			move.Mover = &TurnRobot{Robot: position, Direction: Left}
+		case "right":
+			move.Mover = &TurnRobot{Robot: position, Direction: Right}
+		default:
+			return move, fmt.Errorf("move %q: turn must be left or right", s)
+		}
+	default:
+		return move, fmt.Errorf("move %q isn't place:q,r:DIR, advance:q,r or turn:q,r:left|right", s)
+	}
+	return move, nil
+}
+
+var directionsByName = func() map[string]Pair {
+	byName := make(map[string]Pair, len(directionNames))
+	for direction, name := range directionNames {
+		byName[name] = direction
+	}
+	return byName
+}()
+
+func parsePair(s string) (Pair, error) {
+	q, r, found := strings.Cut(s, ",")
+	if !found {
+		return Pair{}, fmt.Errorf("position %q isn't q,r", s)
+	}
+	qValue, err := strconv.Atoi(q)
+	if err != nil {
+		return Pair{}, fmt.Errorf("position %q isn't q,r", s)
+	}
+	rValue, err := strconv.Atoi(r)
+	if err != nil {
+		return Pair{}, fmt.Errorf("position %q isn't q,r", s)
+	}
+	return Pair{Q: qValue, R: rValue}, nil
+}
@@ -0,0 +1,57 @@
+package lockitdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatMove(t *testing.T) {
+	testCases := []struct {
+		move     GameMove
+		notation string
+	}{
+		{GameMove{Mover: &PlaceRobot{Robot: Pair{0, -5}, Direction: SE}}, "place:0,-5:SE"},
+		{GameMove{Mover: &AdvanceRobot{Robot: Pair{-2, 3}}}, "advance:-2,3"},
+		{GameMove{Mover: &TurnRobot{Robot: Pair{1, 0}, Direction: Left}}, "turn:1,0:left"},
+		{GameMove{Mover: &TurnRobot{Robot: Pair{1, 0}, Direction: Right}}, "turn:1,0:right"},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.notation, FormatMove(tc.move))
+		move, err := ParseMove(tc.notation, 1)
+		assert.Nil(t, err)
+		assert.Equal(t, tc.move.Mover, move.Mover)
+		assert.Equal(t, PlayerPosition(1), move.Player)
+	}
+
+	// Every possible move has its own notation.
+	game := playRandomGame(t, 8, 20)
+	seen := make(map[string]bool)
+	for _, move := range game.PossibleMoves(nil) {
+		notation := FormatMove(move)
+		assert.False(t, seen[notation], notation)
+		seen[notation] = true
+		parsed, err := ParseMove(notation, move.Player)
+		assert.Nil(t, err)
+		assert.Equal(t, move, parsed)
+	}
+}
+
+func TestParseMoveErrors(t *testing.T) {
+	testCases := []struct {
+		notation string
+		err      string
+	}{
+		{"advance", `move "advance" isn't kind:q,r`},
+		{"advance:1", `move "advance:1": position "1" isn't q,r`},
+		{"advance:a,1", `move "advance:a,1": position "a,1" isn't q,r`},
+		{"place:0,-5:N", `move "place:0,-5:N": unknown direction "N"`},
+		{"turn:0,1:back", `move "turn:0,1:back": turn must be left or right`},
+		{"jump:0,1", `move "jump:0,1" isn't place:q,r:DIR, advance:q,r or turn:q,r:left|right`},
+		{"place:0,-5", `move "place:0,-5" isn't place:q,r:DIR, advance:q,r or turn:q,r:left|right`},
+	}
+	for _, tc := range testCases {
+		_, err := ParseMove(tc.notation, 0)
+		assert.EqualError(t, err, tc.err, tc.notation)
+	}
+}
@@ -0,0 +1,58 @@
+package lockitdown
+
+import (
+	"fmt"
+	"strings"
+)
+
+// render.go draws a game as text, for people to read.
+
+var directionNames = map[Pair]string{
+	NW: "NW",
+	NE: "NE",
+	E:  "E",
+	SE: "SE",
+	SW: "SW",
+	W:  "W",
+}
+
+// Render draws the board, with whose turn it is and the players' scores.
+// Robots are drawn as their player and direction, like 1NE, followed by a *
+// if they are locked down. Arena hexes are drawn as . and corridor hexes as :.
+func (g *GameState) Render() string {
+	var b strings.Builder
+	if g.Winner >= 0 {
+		fmt.Fprintf(&b, "Player %d won\n", g.Winner+1)
+	} else {
+		fmt.Fprintf(&b, "Player %d to move, %d moves left\n", g.PlayerTurn+1, g.MovesThisTurn)
+	}
+	for i, player := range g.Players {
+		fmt.Fprintf(&b, "Player %d: %d points, %d robots placed\n", i+1, player.Points, player.PlacedRobots)
+	}
+
+	corridor := g.GameDef.Board.HexaBoard.ArenaRadius + 1
+	for r := -corridor; r <= corridor; r++ {
+		line := strings.Repeat("  ", intAbs(r))
+		for q := intMax(-corridor, -r-corridor); q <= intMin(corridor, corridor-r); q++ {
+			line += renderHex(g, Pair{q, r})
+		}
+		b.WriteString(strings.TrimRight(line, " "))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func renderHex(g *GameState, hex Pair) string {
+	robot := g.RobotAt(hex)
+	if robot == nil {
+		if g.isCorridor(hex) {
+			return " :  "
+		}
+		return " .  "
+	}
+	locked := " "
+	if robot.IsLockedDown {
+		locked = "*"
+	}
+	return fmt.Sprintf("%d%-2s%s", robot.Player+1, directionNames[robot.Direction], locked)
+}
@@ -0,0 +1,37 @@
+package lockitdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	game := NewGame(GameDef{
+		Board:           Board{HexaBoard: BoardType{ArenaRadius: 1}},
+		Players:         2,
+		MovesPerTurn:    3,
+		RobotsPerPlayer: 6,
+		WinCondition:    "Elimination",
+	})
+	game.Robots = append(game.Robots,
+		Robot{Position: Pair{0, -2}, Direction: SE, Player: 0, IsBeamEnabled: true},
+		Robot{Position: Pair{0, 0}, Direction: NE, Player: 1, IsLockedDown: true},
+	)
+	game.Players[0].PlacedRobots = 1
+	game.Players[1].PlacedRobots = 1
+	game.Players[1].Points = 2
+
+	expected := "Player 1 to move, 3 moves left\n" +
+		"Player 1: 0 points, 1 robots placed\n" +
+		"Player 2: 2 points, 1 robots placed\n" +
+		"    1SE  :   :\n" +
+		"   :   .   .   :\n" +
+		" :   .  2NE* .   :\n" +
+		"   :   .   .   :\n" +
+		"     :   :   :\n"
+	assert.Equal(t, expected, game.Render())
+
+	game.Winner = 1
+	assert.Contains(t, game.Render(), "Player 2 won\n")
+}
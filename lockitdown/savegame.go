@@ -0,0 +1,203 @@
+package lockitdown
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// savegame.go saves a game with everything needed to carry on with it,
+// including the moves that can be undone. Unlike the transport format,
+// players are counted from 0, as in GameState.
+
+// SaveVersion is the version of the save format written by SaveGame.
+const SaveVersion = 1
+
+type (
+	savedGame struct {
+		Version          int            `json:"version"`
+		GameDef          GameDef        `json:"gameDef"`
+		Players          []Player       `json:"players"`
+		Robots           []savedRobot   `json:"robots"`
+		PlayerTurn       PlayerPosition `json:"playerTurn"`
+		MovesThisTurn    int            `json:"movesThisTurn"`
+		RequiresTieBreak bool           `json:"requiresTieBreak"`
+		Winner           int            `json:"winner"`
+		// History is the undo stack, oldest first. Each entry is the state
+		// before the move was made.
+		History []savedHistory `json:"history"`
+	}
+
+	savedHistory struct {
+		Player        PlayerPosition `json:"player"`
+		Move          *BoardbotsMove `json:"move"`
+		Players       []Player       `json:"players"`
+		Robots        []savedRobot   `json:"robots"`
+		PlayerTurn    PlayerPosition `json:"playerTurn"`
+		MovesThisTurn int            `json:"movesThisTurn"`
+		Winner        int            `json:"winner"`
+		// RequiresTieBreak is missing from older saves, where it's false.
+		RequiresTieBreak bool `json:"requiresTieBreak,omitempty"`
+	}
+
+	savedRobot struct {
+		Position      Pair           `json:"position"`
+		Direction     Pair           `json:"direction"`
+		IsBeamEnabled bool           `json:"isBeamEnabled"`
+		IsLockedDown  bool           `json:"isLockedDown"`
+		Player        PlayerPosition `json:"player"`
+	}
+)
+
+// SaveGame serializes the complete game, so LoadGame gives back an equal
+// game, which can undo the same moves.
+func SaveGame(g *GameState) ([]byte, error) {
+	saved := savedGame{
+		Version:          SaveVersion,
+		GameDef:          g.GameDef,
+		Players:          make([]Player, len(g.Players)),
+		Robots:           saveRobots(g.Robots),
+		PlayerTurn:       g.PlayerTurn,
+		MovesThisTurn:    g.MovesThisTurn,
+		RequiresTieBreak: g.RequiresTieBreak,
+		Winner:           g.Winner,
+		History:          make([]savedHistory, len(g.saveStack)),
+	}
+	for i, player := range g.Players {
+		saved.Players[i] = *player
+	}
+	for i, save := range g.saveStack {
+		history := savedHistory{
+			Player:        save.move.player,
+			Players:       save.players,
+			Robots:        saveRobots(save.bots),
+			PlayerTurn:    save.player,
+			MovesThisTurn: save.movesThisTurn,
+			Winner:        save.winner,
+
+			RequiresTieBreak: save.requiresTieBreak,
+		}
+		if move, ok := save.move.gameMove(); ok {
+			transport := move.ToTransport()
+			history.Move = &transport
+		}
+		saved.History[i] = history
+	}
+	return json.Marshal(saved)
+}
+
+// LoadGame reads a game saved by SaveGame.
+func LoadGame(b []byte) (*GameState, error) {
+	var saved savedGame
+	if err := json.Unmarshal(b, &saved); err != nil {
+		return nil, err
+	}
+	if saved.Version != SaveVersion {
+		return nil, fmt.Errorf("unsupported save version %d, want %d", saved.Version, SaveVersion)
+	}
+	if err := saved.GameDef.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid game definition: %w", err)
+	}
+	if len(saved.Players) != saved.GameDef.Players {
+		return nil, fmt.Errorf("game is for %d players, has %d", saved.GameDef.Players, len(saved.Players))
+	}
+	if err := checkTurn(saved.PlayerTurn, saved.Winner, len(saved.Players)); err != nil {
+		return nil, err
+	}
+
+	robots, err := loadRobots(saved.Robots, len(saved.Players))
+	if err != nil {
+		return nil, err
+	}
+	game := &GameState{
+		GameDef:          saved.GameDef,
+		Players:          make([]*Player, len(saved.Players)),
+		Robots:           robots,
+		PlayerTurn:       saved.PlayerTurn,
+		MovesThisTurn:    saved.MovesThisTurn,
+		RequiresTieBreak: saved.RequiresTieBreak,
+		Winner:           saved.Winner,
+		saveStack:        make([]SaveState, 0, len(saved.History)),
+	}
+	for i := range saved.Players {
+		player := saved.Players[i]
+		game.Players[i] = &player
+	}
+
+	for i, history := range saved.History {
+		if len(history.Players) != len(saved.Players) {
+			return nil, fmt.Errorf("history %d has %d players, want %d", i, len(history.Players), len(saved.Players))
+		}
+		if err := checkTurn(history.PlayerTurn, history.Winner, len(saved.Players)); err != nil {
+			return nil, fmt.Errorf("history %d: %w", i, err)
+		}
+		if history.Player < 0 || int(history.Player) >= len(saved.Players) {
+			return nil, fmt.Errorf("history %d: move by unknown player %d", i, history.Player)
+		}
+		bots, err := loadRobots(history.Robots, len(saved.Players))
+		if err != nil {
+			return nil, fmt.Errorf("history %d: %w", i, err)
+		}
+		save := SaveState{
+			players:       history.Players,
+			bots:          bots,
+			movesThisTurn: history.MovesThisTurn,
+			player:        history.PlayerTurn,
+			winner:        history.Winner,
+			move:          savedMove{player: history.Player},
+
+			requiresTieBreak: history.RequiresTieBreak,
+		}
+		if history.Move != nil {
+			move, err := MoveFromTransport(*history.Move, history.Player)
+			if err != nil {
+				return nil, fmt.Errorf("history %d: %w", i, err)
+			}
+			save.move = saveMove(&move)
+		}
+		game.saveStack = append(game.saveStack, save)
+	}
+	return game, nil
+}
+
+func saveRobots(robots []Robot) []savedRobot {
+	saved := make([]savedRobot, len(robots))
+	for i, robot := range robots {
+		saved[i] = savedRobot{
+			Position:      robot.Position,
+			Direction:     robot.Direction,
+			IsBeamEnabled: robot.IsBeamEnabled,
+			IsLockedDown:  robot.IsLockedDown,
+			Player:        robot.Player,
+		}
+	}
+	return saved
+}
+
+// checkTurn checks the player to move and the winner, -1 if there's none,
+// are players of the game.
+func checkTurn(playerTurn PlayerPosition, winner, players int) error {
+	if playerTurn < 0 || int(playerTurn) >= players {
+		return fmt.Errorf("unknown player %d to move", playerTurn)
+	}
+	if winner < -1 || winner >= players {
+		return fmt.Errorf("unknown winner %d", winner)
+	}
+	return nil
+}
+
+func loadRobots(saved []savedRobot, players int) ([]Robot, error) {
+	robots := make([]Robot, len(saved))
+	for i, robot := range saved {
+		if robot.Player < 0 || int(robot.Player) >= players {
+			return nil, fmt.Errorf("robot at %s belongs to unknown player %d", robot.Position, robot.Player)
+		}
+		robots[i] = Robot{
+			Position:      robot.Position,
+			Direction:     robot.Direction,
+			IsBeamEnabled: robot.IsBeamEnabled,
+			IsLockedDown:  robot.IsLockedDown,
+			Player:        robot.Player,
+		}
+	}
+	return robots, nil
+}
@@ -0,0 +1,101 @@
+package lockitdown
+
+import (
+	"math/rand"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// playRandomGame makes up to moves random moves, stopping if the game is won.
+func playRandomGame(t *testing.T, seed int64, moves int) *GameState {
+	rng := rand.New(rand.NewSource(seed))
+	game := NewGame(DefaultGameDef)
+	for i := 0; i < moves && game.Winner < 0; i++ {
+		possible := game.PossibleMoves(nil)
+		if len(possible) == 0 {
+			break
+		}
+		move := possible[rng.Intn(len(possible))]
+		if err := game.Move(&move); err != nil {
+			assert.GreaterOrEqual(t, game.Winner, 0, err.Error())
+		}
+	}
+	return game
+}
+
+func TestSaveGame(t *testing.T) {
+	for _, seed := range []int64{1, 2, 3} {
+		game := playRandomGame(t, seed, 40)
+
+		b, err := SaveGame(game)
+		assert.Nil(t, err)
+		loaded, err := LoadGame(b)
+		assert.Nil(t, err)
+		assert.Equal(t, game, loaded)
+		assert.Len(t, loaded.History(), len(game.saveStack))
+		assert.Equal(t, game.History(), loaded.History())
+
+		// Both games undo the same moves.
+		history := loaded.History()
+		for i := len(history) - 1; i >= 0; i-- {
+			assert.Nil(t, game.Undo(&history[i]))
+			assert.Nil(t, loaded.Undo(&history[i]))
+			assert.Equal(t, game.Render(), loaded.Render())
+		}
+		assert.Equal(t, NewGame(DefaultGameDef).Render(), loaded.Render())
+	}
+}
+
+func TestHistory(t *testing.T) {
+	game := NewGame(DefaultGameDef)
+	moves := []GameMove{
+		{Player: 0, Mover: &PlaceRobot{Robot: Pair{0, -5}, Direction: SE}},
+		{Player: 1, Mover: &PlaceRobot{Robot: Pair{0, 5}, Direction: NW}},
+		{Player: 0, Mover: &AdvanceRobot{Robot: Pair{0, -5}}},
+		{Player: 0, Mover: &TurnRobot{Robot: Pair{0, -4}, Direction: Left}},
+	}
+	for _, move := range moves {
+		assert.Nil(t, game.Move(&move))
+	}
+	assert.Equal(t, moves, game.History())
+
+	// Failed moves aren't part of the history, and don't change the game.
+	before := game.Render()
+	assert.NotNil(t, game.Move(&GameMove{Player: 0, Mover: &AdvanceRobot{Robot: Pair{1, 1}}}))
+	assert.Equal(t, moves, game.History())
+	assert.Equal(t, before, game.Render())
+}
+
+func TestLoadGameErrors(t *testing.T) {
+	b, err := SaveGame(playRandomGame(t, 1, 10))
+	assert.Nil(t, err)
+	saved := string(b)
+	playerTurn := regexp.MustCompile(`"playerTurn":\d+`)
+
+	testcases := []struct {
+		name string
+		save string
+		err  string
+	}{
+		{"not json", "save", "invalid character"},
+		{"old version", strings.Replace(saved, `"version":1`, `"version":0`, 1), "unsupported save version 0"},
+		{"bad game", strings.Replace(saved, `"arenaRadius":4`, `"arenaRadius":0`, 1), "invalid game definition"},
+		{"unknown player", strings.Replace(saved, `"player":1}`, `"player":7}`, 1), "unknown player 7"},
+		{"bad move", strings.Replace(saved, `"action":"Advance"`, `"action":"Retreat"`, 1), "unknown action"},
+		{"unknown player to move", playerTurn.ReplaceAllString(saved, `"playerTurn":2`), "unknown player 2 to move"},
+		{"negative player to move", playerTurn.ReplaceAllString(saved, `"playerTurn":-1`), "unknown player -1 to move"},
+		{"unknown winner", strings.Replace(saved, `"winner":-1`, `"winner":2`, 1), "unknown winner 2"},
+		{"negative winner", strings.Replace(saved, `"winner":-1`, `"winner":-2`, 1), "unknown winner -2"},
+		{"move by unknown player", strings.Replace(saved, `{"player":0,"move"`, `{"player":4,"move"`, 1), "move by unknown player 4"},
+		{"unknown history winner", strings.Replace(saved, `"winner":-1}`, `"winner":5}`, 1), "unknown winner 5"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := LoadGame([]byte(tc.save))
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+}
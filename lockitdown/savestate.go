@@ -5,4 +5,58 @@ type SaveState struct {
 	bots          []Robot
 	movesThisTurn int
 	player        PlayerPosition
+	winner        int
+	// requiresTieBreak is restored with the rest, a move needing a tie
+	// break is taken back.
+	requiresTieBreak bool
+	move             savedMove
+}
+
+// savedMove is a copy of the move made from a SaveState. Movers are pooled
+// and reused by the search, so the history can't keep them.
+type savedMove struct {
+	player    PlayerPosition
+	kind      moveKind
+	robot     Pair
+	direction Pair
+	turn      TurnDirection
+}
+
+type moveKind int
+
+const (
+	noMove moveKind = iota
+	advanceMove
+	turnMove
+	placeMove
+)
+
+func saveMove(move *GameMove) savedMove {
+	saved := savedMove{player: move.Player}
+	switch m := move.Mover.(type) {
+	case *AdvanceRobot:
+		saved.kind, saved.robot = advanceMove, m.Robot
+	case *TurnRobot:
+		saved.kind, saved.robot, saved.turn = turnMove, m.Robot, m.Direction
+	case *PlaceRobot:
+		saved.kind, saved.robot, saved.direction = placeMove, m.Robot, m.Direction
+	}
+	return saved
+}
+
+// gameMove makes a new GameMove from the copy, or returns false if the move
+// wasn't one of the known movers.
+func (m savedMove) gameMove() (GameMove, bool) {
+	move := GameMove{Player: m.player}
+	switch m.kind {
+	case advanceMove:
+		move.Mover = &AdvanceRobot{Robot: m.robot}
+	case turnMove:
+		move.Mover = &TurnRobot{Robot: m.robot, Direction: m.turn}
+	case placeMove:
+		move.Mover = &PlaceRobot{Robot: m.robot, Direction: m.direction}
+	default:
+		return move, false
+	}
+	return move, true
 }
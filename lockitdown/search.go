@@ -0,0 +1,331 @@
+package lockitdown
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+)
+
+// search.go finds the best move with an iterative deepening alpha-beta
+// search, keeping the principal variation it expects to be played.
+
+// WinScore is the score of a won game, sooner wins score higher.
+const WinScore = 1_000_000
+
+// maxTableSize limits the positions the transposition table remembers, so
+// long searches don't run out of memory.
+const maxTableSize = 1 << 20
+
+type (
+	// SearchOptions bound a search. The search deepens one ply at a time
+	// until MaxDepth, or until MoveTime passes. The first ply is always
+	// searched completely.
+	SearchOptions struct {
+		// Evaluator scores positions, defaults to ScoreGameState.
+		Evaluator Evaluator
+		MaxDepth  int
+		// MoveTime limits the search, zero only limits it by depth and the
+		// context.
+		MoveTime time.Duration
+		// Progress, if set, is called with the result of each completed
+		// depth, while the search carries on.
+		Progress func(SearchResult)
+	}
+
+	// SearchResult is the outcome of the deepest completed search.
+	SearchResult struct {
+		// Move is the best move for the player to move. Its Mover is nil if
+		// the game is over or the player has no moves.
+		Move GameMove
+		// Score is the evaluation for the player to move, after the PV.
+		Score int
+		// Depth is the deepest completed search.
+		Depth int
+		// PV is the principal variation, the moves both players are
+		// expected to make, starting with Move.
+		PV []GameMove
+		// Nodes counts the positions visited, over every depth.
+		Nodes int
+		// TableProbes counts the lookups in the transposition table, and
+		// TableHits the ones that found the best move of a position
+		// searched before.
+		TableProbes, TableHits int
+	}
+
+	// MoveAnalysis is the searched score of one move.
+	MoveAnalysis struct {
+		Move GameMove
+		// Score is the evaluation for the player making the move, as in
+		// SearchResult.
+		Score int
+		// PV is the principal variation, starting with Move.
+		PV []GameMove
+	}
+
+	searcher struct {
+		ctx       context.Context
+		game      *GameState
+		player    PlayerPosition
+		evaluator Evaluator
+		pv        []GameMove
+		nodes     int
+		// table is the transposition table, the best move found for each
+		// position, to search it first when the position comes up again.
+		table        map[uint64]savedMove
+		probes, hits int
+	}
+)
+
+// Search finds the best move for the player to move. The game is left as it
+// was found.
+func Search(ctx context.Context, game *GameState, opts SearchOptions) SearchResult {
+	if opts.MoveTime > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.MoveTime)
+		defer cancel()
+	}
+
+	s := &searcher{
+		game:      game,
+		player:    game.PlayerTurn,
+		evaluator: opts.Evaluator,
+		table:     make(map[uint64]savedMove),
+	}
+	if s.evaluator == nil {
+		s.evaluator = ScoreGameState
+	}
+	var result SearchResult
+	for depth := 1; depth <= opts.MaxDepth; depth++ {
+		// The first ply always completes, so there's a move to play.
+		s.ctx = ctx
+		if depth == 1 {
+			s.ctx = context.Background()
+		}
+		score, pv, complete := s.search(depth, 0, math.MinInt, math.MaxInt)
+		if !complete {
+			break
+		}
+		s.pv = pv
+		result = SearchResult{
+			Score: score,
+			Depth: depth,
+			PV:    pv,
+		}
+		if len(pv) > 0 {
+			result.Move = pv[0]
+		}
+		if opts.Progress != nil {
+			progress := result
+			progress.Nodes, progress.TableProbes, progress.TableHits = s.nodes, s.probes, s.hits
+			opts.Progress(progress)
+		}
+		if len(pv) < depth {
+			// The game ends before the search depth, deeper searches can't
+			// find anything else.
+			break
+		}
+	}
+	result.Nodes, result.TableProbes, result.TableHits = s.nodes, s.probes, s.hits
+	return result
+}
+
+// Analyze searches every move of the player to move, best first. Unlike
+// Search, each move is searched with a full window, so every score is exact.
+// Returns the analysis of the deepest completed search, and its depth.
+func Analyze(ctx context.Context, game *GameState, opts SearchOptions) ([]MoveAnalysis, int) {
+	if opts.MoveTime > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.MoveTime)
+		defer cancel()
+	}
+
+	s := &searcher{
+		game:      game,
+		player:    game.PlayerTurn,
+		evaluator: opts.Evaluator,
+		table:     make(map[uint64]savedMove),
+	}
+	if s.evaluator == nil {
+		s.evaluator = ScoreGameState
+	}
+	if game.Winner >= 0 {
+		return nil, 0
+	}
+	moves := game.PossibleMoves(nil)
+	analysis := make([]MoveAnalysis, 0, len(moves))
+	for i := range moves {
+		analysis = append(analysis, MoveAnalysis{Move: moves[i]})
+	}
+
+	depth := 0
+	for depth < opts.MaxDepth {
+		s.ctx = ctx
+		if depth == 0 {
+			s.ctx = context.Background()
+		}
+		deeper, complete := s.analyze(analysis, depth+1)
+		if !complete {
+			break
+		}
+		analysis = deeper
+		depth++
+	}
+	sort.SliceStable(analysis, func(i, j int) bool {
+		return analysis[i].Score > analysis[j].Score
+	})
+	return analysis, depth
+}
+
+// analyze searches each move depth plies deep, starting with the previous
+// principal variation of the move.
+func (s *searcher) analyze(previous []MoveAnalysis, depth int) ([]MoveAnalysis, bool) {
+	analysis := make([]MoveAnalysis, 0, len(previous))
+	for _, prev := range previous {
+		move := prev.Move
+		if err := s.game.Move(&move); err != nil && s.game.Winner < 0 {
+			continue
+		}
+		s.pv = prev.PV
+		score, pv, complete := s.search(depth-1, 1, math.MinInt, math.MaxInt)
+		s.game.Undo(&move)
+		if !complete {
+			return nil, false
+		}
+		analysis = append(analysis, MoveAnalysis{
+			Move:  move,
+			Score: score,
+			PV:    append([]GameMove{move}, pv...),
+		})
+	}
+	return analysis, true
+}
+
+// search returns the score of the game after depth plies, and the moves
+// that lead to it. Returns false if the search was cancelled.
+func (s *searcher) search(depth, ply, alpha, beta int) (int, []GameMove, bool) {
+	s.nodes++
+	if s.ctx.Err() != nil {
+		return 0, nil, false
+	}
+	if s.game.Winner >= 0 {
+		if PlayerPosition(s.game.Winner) == s.player {
+			return WinScore + depth, nil, true
+		}
+		return -WinScore - depth, nil, true
+	}
+	if depth == 0 {
+		return s.evaluator(s.game, s.player), nil, true
+	}
+	key := s.game.positionKey()
+	s.probes++
+	tableMove, found := s.table[key]
+	if found {
+		s.hits++
+	}
+	moves := s.orderMoves(s.game.PossibleMoves(nil), ply, tableMove, found)
+	if len(moves) == 0 {
+		return s.evaluator(s.game, s.player), nil, true
+	}
+
+	maximize := s.game.PlayerTurn == s.player
+	best := math.MaxInt
+	if maximize {
+		best = math.MinInt
+	}
+	var bestPV []GameMove
+	for i := range moves {
+		move := moves[i]
+		if err := s.game.Move(&move); err != nil && s.game.Winner < 0 {
+			continue
+		}
+		score, pv, complete := s.search(depth-1, ply+1, alpha, beta)
+		s.game.Undo(&move)
+		if !complete {
+			return 0, nil, false
+		}
+
+		if (maximize && score > best) || (!maximize && score < best) {
+			best = score
+			bestPV = append([]GameMove{move}, pv...)
+		}
+		if maximize {
+			alpha = intMax(alpha, score)
+		} else {
+			beta = intMin(beta, score)
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+	if bestPV == nil {
+		return s.evaluator(s.game, s.player), nil, true
+	}
+	if found || len(s.table) < maxTableSize {
+		s.table[key] = saveMove(&bestPV[0])
+	}
+	return best, bestPV, true
+}
+
+// orderMoves searches the move of the previous principal variation first,
+// it's likely the best and prunes the most, then the best move of the
+// position from the transposition table.
+func (s *searcher) orderMoves(moves []GameMove, ply int, tableMove savedMove, found bool) []GameMove {
+	front := 0
+	if ply < len(s.pv) && moveToFront(moves, front, saveMove(&s.pv[ply])) {
+		front++
+	}
+	if found {
+		moveToFront(moves, front, tableMove)
+	}
+	return moves
+}
+
+// moveToFront swaps the move into moves[front], if it's at or after it.
+func moveToFront(moves []GameMove, front int, move savedMove) bool {
+	for i := front; i < len(moves); i++ {
+		if saveMove(&moves[i]) == move {
+			moves[front], moves[i] = moves[i], moves[front]
+			return true
+		}
+	}
+	return false
+}
+
+// positionKey hashes everything about the game that decides the moves and
+// their outcomes. Robots are hashed separately and summed, so their order
+// doesn't matter.
+func (g *GameState) positionKey() uint64 {
+	key := mix(uint64(g.PlayerTurn) | uint64(g.MovesThisTurn)<<8 | uint64(uint8(g.Winner))<<16 | boolBit(g.RequiresTieBreak)<<24 | 1<<63)
+	for i, player := range g.Players {
+		key += mix(uint64(i) | uint64(uint16(player.Points))<<8 | uint64(uint16(player.PlacedRobots))<<24 | 1<<62)
+	}
+	for _, robot := range g.Robots {
+		key += mix(uint64(uint8(robot.Position.Q)) |
+			uint64(uint8(robot.Position.R))<<8 |
+			uint64(uint8(robot.Direction.Q))<<16 |
+			uint64(uint8(robot.Direction.R))<<24 |
+			uint64(robot.Player)<<32 |
+			boolBit(robot.IsLockedDown)<<40 |
+			boolBit(robot.IsBeamEnabled)<<41)
+	}
+	return key
+}
+
+// mix is the finalizer of splitmix64, it spreads the bits of x over the
+// whole key.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+func boolBit(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
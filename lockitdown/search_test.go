@@ -0,0 +1,207 @@
+package lockitdown
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// referenceMinimax is a plain minimax, to check the search against.
+func referenceMinimax(game *GameState, player PlayerPosition, depth int) int {
+	if game.Winner >= 0 {
+		if PlayerPosition(game.Winner) == player {
+			return WinScore + depth
+		}
+		return -WinScore - depth
+	}
+	moves := game.PossibleMoves(nil)
+	if depth == 0 || len(moves) == 0 {
+		return ScoreGameState(game, player)
+	}
+	maximize := game.PlayerTurn == player
+	best := math.MaxInt
+	if maximize {
+		best = math.MinInt
+	}
+	for _, move := range moves {
+		if err := game.Move(&move); err != nil && game.Winner < 0 {
+			continue
+		}
+		score := referenceMinimax(game, player, depth-1)
+		game.Undo(&move)
+		if (maximize && score > best) || (!maximize && score < best) {
+			best = score
+		}
+	}
+	return best
+}
+
+func TestSearch(t *testing.T) {
+	for _, seed := range []int64{1, 2, 3} {
+		game := playRandomGame(t, seed, 12)
+		before, err := SaveGame(game)
+		assert.Nil(t, err)
+
+		result := Search(context.Background(), game, SearchOptions{MaxDepth: 3})
+		after, err := SaveGame(game)
+		assert.Nil(t, err)
+		assert.Equal(t, string(before), string(after), "search leaves the game as it was")
+
+		assert.Equal(t, 3, result.Depth)
+		assert.Equal(t, referenceMinimax(game, game.PlayerTurn, 3), result.Score)
+		assert.Len(t, result.PV, 3)
+		assert.Equal(t, result.PV[0], result.Move)
+		assert.Greater(t, result.Nodes, 0)
+
+		// The principal variation is playable, and scores as promised.
+		player := game.PlayerTurn
+		for i := range result.PV {
+			assert.Nil(t, game.Move(&result.PV[i]))
+		}
+		assert.Equal(t, result.Score, ScoreGameState(game, player))
+	}
+}
+
+func TestSearchProgress(t *testing.T) {
+	game := playRandomGame(t, 5, 12)
+
+	var progress []SearchResult
+	result := Search(context.Background(), game, SearchOptions{
+		MaxDepth: 3,
+		Progress: func(r SearchResult) { progress = append(progress, r) },
+	})
+	assert.Len(t, progress, 3)
+	for i, p := range progress {
+		assert.Equal(t, i+1, p.Depth)
+		assert.Len(t, p.PV, i+1)
+		if i > 0 {
+			assert.Greater(t, p.Nodes, progress[i-1].Nodes)
+		}
+	}
+	assert.Equal(t, result.Score, progress[2].Score)
+	assert.Equal(t, result.PV, progress[2].PV)
+	assert.Equal(t, result.Nodes, progress[2].Nodes)
+}
+
+func TestSearchMoveTime(t *testing.T) {
+	game := NewGame(DefaultGameDef)
+
+	start := time.Now()
+	result := Search(context.Background(), game, SearchOptions{MaxDepth: 50, MoveTime: 20 * time.Millisecond})
+	assert.Less(t, time.Since(start), time.Second)
+	assert.GreaterOrEqual(t, result.Depth, 1)
+	assert.Less(t, result.Depth, 50)
+	assert.NotNil(t, result.Move.Mover)
+
+	// The first ply is searched, even without time.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result = Search(ctx, game, SearchOptions{MaxDepth: 5})
+	assert.Equal(t, 1, result.Depth)
+	assert.NotNil(t, result.Move.Mover)
+}
+
+func TestSearchGameOver(t *testing.T) {
+	game := NewGame(DefaultGameDef)
+	game.Winner = 1
+
+	result := Search(context.Background(), game, SearchOptions{MaxDepth: 3})
+	assert.Nil(t, result.Move.Mover)
+	assert.Empty(t, result.PV)
+	assert.Equal(t, -WinScore-1, result.Score)
+}
+
+func TestAnalyze(t *testing.T) {
+	game := playRandomGame(t, 4, 12)
+	before, err := SaveGame(game)
+	assert.Nil(t, err)
+
+	analysis, depth := Analyze(context.Background(), game, SearchOptions{MaxDepth: 2})
+	after, err := SaveGame(game)
+	assert.Nil(t, err)
+	assert.Equal(t, string(before), string(after), "analysis leaves the game as it was")
+
+	assert.Equal(t, 2, depth)
+	assert.Len(t, analysis, len(game.PossibleMoves(nil)))
+	for i, move := range analysis {
+		if i > 0 {
+			assert.LessOrEqual(t, move.Score, analysis[i-1].Score, "sorted best first")
+		}
+		assert.Equal(t, move.Move, move.PV[0])
+
+		assert.Nil(t, game.Move(&move.Move))
+		assert.Equal(t, referenceMinimax(game, move.Move.Player, 1), move.Score)
+		game.Undo(&move.Move)
+	}
+
+	result := Search(context.Background(), game, SearchOptions{MaxDepth: 2})
+	assert.Equal(t, result.Score, analysis[0].Score)
+}
+
+func TestAnalyzeCancelled(t *testing.T) {
+	game := NewGame(DefaultGameDef)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	analysis, depth := Analyze(ctx, game, SearchOptions{MaxDepth: 3})
+	assert.Equal(t, 1, depth)
+	assert.Len(t, analysis, len(game.PossibleMoves(nil)))
+
+	game.Winner = 0
+	analysis, depth = Analyze(context.Background(), game, SearchOptions{MaxDepth: 3})
+	assert.Empty(t, analysis)
+	assert.Equal(t, 0, depth)
+}
+
+func TestSearchLeavesGame(t *testing.T) {
+	// A move of the position needs a tie break, which the search skips.
+	game, _ := tieBreakPosition(t)
+	before, err := SaveGame(game)
+	assert.Nil(t, err)
+
+	Search(context.Background(), game, SearchOptions{MaxDepth: 2})
+	after, err := SaveGame(game)
+	assert.Nil(t, err)
+	assert.JSONEq(t, string(before), string(after))
+
+	Analyze(context.Background(), game, SearchOptions{MaxDepth: 2})
+	after, err = SaveGame(game)
+	assert.Nil(t, err)
+	assert.JSONEq(t, string(before), string(after))
+}
+
+func TestSearchTable(t *testing.T) {
+	game := playRandomGame(t, 6, 12)
+
+	result := Search(context.Background(), game, SearchOptions{MaxDepth: 3})
+	assert.Greater(t, result.TableProbes, 0)
+	assert.Greater(t, result.TableHits, 0)
+	assert.LessOrEqual(t, result.TableHits, result.TableProbes)
+}
+
+func TestPositionKey(t *testing.T) {
+	game := playRandomGame(t, 7, 20)
+	key := game.positionKey()
+
+	// The order of the robots doesn't matter.
+	reversed := *game
+	reversed.Robots = make([]Robot, len(game.Robots))
+	for i, robot := range game.Robots {
+		reversed.Robots[len(game.Robots)-1-i] = robot
+	}
+	assert.Equal(t, key, reversed.positionKey())
+
+	seen := map[uint64]bool{key: true}
+	for _, move := range game.PossibleMoves(nil) {
+		if err := game.Move(&move); err != nil && game.Winner < 0 {
+			continue
+		}
+		assert.False(t, seen[game.positionKey()], "%s makes a new position", move.Mover)
+		seen[game.positionKey()] = true
+		game.Undo(&move)
+		assert.Equal(t, key, game.positionKey(), "undo %s", move.Mover)
+	}
+}
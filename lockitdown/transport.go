@@ -1,6 +1,8 @@
 package lockitdown
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
 )
@@ -12,18 +14,124 @@ type (
 		IsLocked      bool `json:"isLocked"`
 		IsBeamEnabled bool `json:"isBeamEnabled"`
 	}
-	TransportRobots []interface{}
-	TransportState  struct {
+	// TransportRobots is a robot on the board, sent as a [position, robot]
+	// pair.
+	TransportRobots struct {
+		Position Pair
+		Robot    TransportRobot
+	}
+	TransportState struct {
 		GameDef          GameDef           `json:"gameDef"`
 		Players          []Player          `json:"players"`
 		Robots           []TransportRobots `json:"robots"`
 		PlayerTurn       int               `json:"playerTurn"`
-		Status           interface{}       `json:"status"`
+		Status           Status            `json:"status"`
 		MovesThisTurn    int               `json:"movesThisTurn"`
 		RequiresTieBreak bool              `json:"requiresTieBreak"`
 	}
+
+	// Status is OnGoing while the game is played, and then the position of
+	// the winner.
+	Status string
 )
 
+const OnGoing Status = "OnGoing"
+
+// Winner returns the position of the winner, or -1 while the game is on.
+func (s Status) Winner() int {
+	winner, err := strconv.Atoi(string(s))
+	if err != nil || winner < 0 {
+		return -1
+	}
+	return winner
+}
+
+// UnmarshalJSON accepts "OnGoing", or the winner as a number or a string
+// of one.
+func (s *Status) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+	var status string
+	if err := json.Unmarshal(b, &status); err != nil {
+		var winner int
+		if json.Unmarshal(b, &winner) != nil {
+			return fmt.Errorf("invalid status %s, want \"OnGoing\" or a winner", b)
+		}
+		status = strconv.Itoa(winner)
+	}
+	if Status(status) != OnGoing && Status(status).Winner() < 0 {
+		return fmt.Errorf("invalid status %q, want \"OnGoing\" or a winner", status)
+	}
+	*s = Status(status)
+	return nil
+}
+
+func (r TransportRobots) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]any{r.Position, r.Robot})
+}
+
+func (r *TransportRobots) UnmarshalJSON(b []byte) error {
+	var pair []json.RawMessage
+	if err := json.Unmarshal(b, &pair); err != nil {
+		return fmt.Errorf("robot must be a [position, robot] pair: %w", err)
+	}
+	if len(pair) != 2 {
+		return fmt.Errorf("robot must be a [position, robot] pair, has %d elements", len(pair))
+	}
+
+	position, err := decodePair(pair[0])
+	if err != nil {
+		return fmt.Errorf("robot position: %w", err)
+	}
+
+	var robot struct {
+		Player        *int            `json:"player"`
+		Dir           json.RawMessage `json:"dir"`
+		IsLocked      bool            `json:"isLocked"`
+		IsBeamEnabled bool            `json:"isBeamEnabled"`
+	}
+	if err := json.Unmarshal(pair[1], &robot); err != nil {
+		return fmt.Errorf("robot at %s: %w", position, err)
+	}
+	if robot.Player == nil || *robot.Player < 1 {
+		return fmt.Errorf("robot at %s must have a player, counting from 1", position)
+	}
+	dir, err := decodePair(robot.Dir)
+	if err != nil {
+		return fmt.Errorf("robot at %s direction: %w", position, err)
+	}
+
+	*r = TransportRobots{
+		Position: position,
+		Robot: TransportRobot{
+			Player:        *robot.Player,
+			Dir:           dir,
+			IsLocked:      robot.IsLocked,
+			IsBeamEnabled: robot.IsBeamEnabled,
+		},
+	}
+	return nil
+}
+
+// decodePair reads a Pair, requiring both coordinates.
+func decodePair(b json.RawMessage) (Pair, error) {
+	if len(b) == 0 {
+		return Pair{}, errors.New("missing")
+	}
+	var pair struct {
+		Q *int `json:"q"`
+		R *int `json:"r"`
+	}
+	if err := json.Unmarshal(b, &pair); err != nil {
+		return Pair{}, err
+	}
+	if pair.Q == nil || pair.R == nil {
+		return Pair{}, fmt.Errorf("%s must have a q and r", b)
+	}
+	return Pair{Q: *pair.Q, R: *pair.R}, nil
+}
+
 func ConvertToTransport(game *GameState) *TransportState {
 	players := make([]Player, len(game.Players))
 	for i, player := range game.Players {
@@ -33,9 +141,9 @@ func ConvertToTransport(game *GameState) *TransportState {
 	robots := make([]TransportRobots, len(game.Robots))
 	idx := 0
 	for _, robot := range game.Robots {
-		robots[idx] = []interface{}{
-			robot.Position,
-			TransportRobot{
+		robots[idx] = TransportRobots{
+			Position: robot.Position,
+			Robot: TransportRobot{
 				Player:        int(robot.Player) + 1,
 				Dir:           robot.Direction,
 				IsLocked:      robot.IsLockedDown,
@@ -45,11 +153,9 @@ func ConvertToTransport(game *GameState) *TransportState {
 		idx++
 	}
 
-	var status string
-	if game.Winner < 0 {
-		status = "OnGoing"
-	} else {
-		status = fmt.Sprintf("%d", game.Winner)
+	status := OnGoing
+	if game.Winner >= 0 {
+		status = Status(strconv.Itoa(game.Winner))
 	}
 
 	return &TransportState{
@@ -63,6 +169,52 @@ func ConvertToTransport(game *GameState) *TransportState {
 	}
 }
 
+// Validate reports whether the state could be a game of its GameDef: the
+// players and their turn, and robots facing a direction from a hex on the
+// board, one to a hex.
+func (s *TransportState) Validate() error {
+	if err := s.GameDef.Validate(); err != nil {
+		return err
+	}
+	players := s.GameDef.Players
+	if len(s.Players) != players {
+		return fmt.Errorf("game is for %d players, has %d", players, len(s.Players))
+	}
+	if s.PlayerTurn < 1 || s.PlayerTurn > players {
+		return fmt.Errorf("player turn must be between 1 and %d, is %d", players, s.PlayerTurn)
+	}
+	if s.MovesThisTurn < 0 || s.MovesThisTurn > s.GameDef.MovesPerTurn {
+		return fmt.Errorf("moves this turn must be between 0 and %d, is %d", s.GameDef.MovesPerTurn, s.MovesThisTurn)
+	}
+	if winner := s.Status.Winner(); winner >= players {
+		return fmt.Errorf("winner %d isn't a player", winner)
+	}
+	for i, player := range s.Players {
+		if player.PlacedRobots < 0 || player.PlacedRobots > s.GameDef.RobotsPerPlayer {
+			return fmt.Errorf("player %d placed %d robots, of %d", i+1, player.PlacedRobots, s.GameDef.RobotsPerPlayer)
+		}
+	}
+
+	corridor := s.GameDef.Board.HexaBoard.ArenaRadius + 1
+	occupied := make(map[Pair]bool, len(s.Robots))
+	for _, robot := range s.Robots {
+		if robot.Position.Dist() > corridor {
+			return fmt.Errorf("robot at %s is off the board", robot.Position)
+		}
+		if occupied[robot.Position] {
+			return fmt.Errorf("two robots at %s", robot.Position)
+		}
+		occupied[robot.Position] = true
+		if robot.Robot.Player < 1 || robot.Robot.Player > players {
+			return fmt.Errorf("robot at %s belongs to unknown player %d", robot.Position, robot.Robot.Player)
+		}
+		if robot.Robot.Dir.Dist() != 1 {
+			return fmt.Errorf("robot at %s faces %s, which isn't a direction", robot.Position, robot.Robot.Dir)
+		}
+	}
+	return nil
+}
+
 func StateFromTransport(tState *TransportState) *GameState {
 	players := make([]*Player, 0, len(tState.Players))
 	for _, player := range tState.Players {
@@ -74,22 +226,15 @@ func StateFromTransport(tState *TransportState) *GameState {
 
 	robots := make([]Robot, len(tState.Robots))
 	for i, robot := range tState.Robots {
-		tRobot := TransportRobotFromMap(robot[1].(map[string]interface{}))
-		position := PairFromMap(robot[0].(map[string]interface{}))
 		robots[i] = Robot{
-			Position:      position,
-			Direction:     tRobot.Dir,
-			IsBeamEnabled: tRobot.IsBeamEnabled,
-			IsLockedDown:  tRobot.IsLocked,
-			Player:        PlayerPosition(tRobot.Player - 1),
+			Position:      robot.Position,
+			Direction:     robot.Robot.Dir,
+			IsBeamEnabled: robot.Robot.IsBeamEnabled,
+			IsLockedDown:  robot.Robot.IsLocked,
+			Player:        PlayerPosition(robot.Robot.Player - 1),
 		}
 	}
 
-	winner := -1
-	if tState.Status != "OnGoing" {
-		winner, _ = strconv.Atoi(tState.Status.(string))
-	}
-
 	return &GameState{
 		GameDef:          tState.GameDef,
 		Players:          players,
@@ -97,26 +242,54 @@ func StateFromTransport(tState *TransportState) *GameState {
 		PlayerTurn:       PlayerPosition(tState.PlayerTurn - 1),
 		MovesThisTurn:    tState.GameDef.MovesPerTurn - tState.MovesThisTurn,
 		RequiresTieBreak: tState.RequiresTieBreak,
-		Winner:           winner,
+		Winner:           tState.Status.Winner(),
 	}
 }
 
-func PairFromMap(json map[string]interface{}) Pair {
-	return Pair{
-		Q: int(json["q"].(float64)),
-		R: int(json["r"].(float64)),
+// MoveFromTransport reads a move made by player from the wire format of
+// boardbots.dev, the reverse of GameMove.ToTransport. The action can be a
+// transport type, like TurnRobotT, decoded JSON or a json.RawMessage.
+func MoveFromTransport(move BoardbotsMove, player PlayerPosition) (GameMove, error) {
+	action, isRaw := move.Action.(json.RawMessage)
+	if !isRaw {
+		b, err := json.Marshal(move.Action)
+		if err != nil {
+			return GameMove{}, err
+		}
+		action = b
 	}
+	mover, err := moverFromTransport(move.Position, action)
+	if err != nil {
+		return GameMove{}, err
+	}
+	return GameMove{Player: player, Mover: mover}, nil
 }
 
-func TransportRobotFromMap(json map[string]interface{}) TransportRobot {
-	dir := json["dir"].(map[string]interface{})
-	return TransportRobot{
-		Player: int(json["player"].(float64)),
-		Dir: Pair{
-			Q: int(dir["q"].(float64)),
-			R: int(dir["r"].(float64)),
-		},
-		IsLocked:      json["isLocked"].(bool),
-		IsBeamEnabled: json["isBeamEnabled"].(bool),
+// moverFromTransport reads an action, which is one of "Advance",
+// {"Turn": {"side": "Left"|"Right"}} or {"PlaceRobot": {"dir": {...}}}.
+func moverFromTransport(pos Pair, action json.RawMessage) (Mover, error) {
+	var advance string
+	if err := json.Unmarshal(action, &advance); err == nil {
+		if advance != "Advance" {
+			return nil, fmt.Errorf("unknown action %q", advance)
+		}
+		return &AdvanceRobot{Robot: pos}, nil
+	}
+
+	var decoded struct {
+		Turn       *InnerTurnRobotT  `json:"Turn"`
+		PlaceRobot *InnerPlaceRobotT `json:"PlaceRobot"`
+	}
+	if err := json.Unmarshal(action, &decoded); err != nil {
+		return nil, fmt.Errorf("invalid action: %w", err)
+	}
+	switch {
+	case decoded.Turn != nil && decoded.Turn.Side == "Left":
+		return &TurnRobot{Robot: pos, Direction: Left}, nil
+	case decoded.Turn != nil && decoded.Turn.Side == "Right":
+		return &TurnRobot{Robot: pos, Direction: Right}, nil
+	case decoded.PlaceRobot != nil:
+		return &PlaceRobot{Robot: pos, Direction: decoded.PlaceRobot.Dir}, nil
 	}
+	return nil, fmt.Errorf("unknown action %s", string(action))
 }
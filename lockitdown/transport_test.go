@@ -0,0 +1,202 @@
+package lockitdown
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoveFromTransport(t *testing.T) {
+	game := NewGame(DefaultGameDef)
+	for _, move := range []GameMove{
+		{Player: 0, Mover: &PlaceRobot{Robot: Pair{0, -5}, Direction: Pair{0, 1}}},
+		{Player: 1, Mover: &PlaceRobot{Robot: Pair{0, 5}, Direction: Pair{0, -1}}},
+	} {
+		assert.Nil(t, game.Move(&move))
+	}
+
+	moves := game.PossibleMoves(nil)
+	assert.NotEmpty(t, moves)
+	for _, move := range moves {
+		transport := move.ToTransport()
+
+		// Moves made locally.
+		decoded, err := MoveFromTransport(transport, move.Player)
+		assert.Nil(t, err)
+		assert.Equal(t, move, decoded)
+
+		// Moves read from the server.
+		b, err := json.Marshal(transport)
+		assert.Nil(t, err)
+		var fromJson BoardbotsMove
+		assert.Nil(t, json.Unmarshal(b, &fromJson))
+		decoded, err = MoveFromTransport(fromJson, move.Player)
+		assert.Nil(t, err)
+		assert.Equal(t, move, decoded)
+	}
+}
+
+func TestMoveFromTransportErrors(t *testing.T) {
+	testcases := []struct {
+		name   string
+		action any
+	}{
+		{"unknown string", "Retreat"},
+		{"unknown side", TurnRobotT{Turn: InnerTurnRobotT{Side: "Around"}}},
+		{"unknown object", map[string]any{"Jump": true}},
+		{"number", 3},
+		{"invalid json", json.RawMessage(`{"Turn":`)},
+		{"unmarshallable", func() {}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := MoveFromTransport(BoardbotsMove{Action: tc.action}, 0)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+const transportJson = `{
+	"gameDef": {"board": {"HexaBoard": {"arenaRadius": 4}}, "numOfPlayers": 2, "movesPerTurn": 3, "robotsPerPlayer": 6, "winCondition": "Elimination"},
+	"players": [{"points": 0, "placedRobots": 1}, {"points": 2, "placedRobots": 1}],
+	"robots": [
+		[{"q": 0, "r": -4}, {"player": 1, "dir": {"q": 0, "r": 1}, "isLocked": false, "isBeamEnabled": true}],
+		[{"q": 4, "r": 0}, {"player": 2, "dir": {"q": -1, "r": 0}, "isLocked": true, "isBeamEnabled": false}]
+	],
+	"playerTurn": 2,
+	"status": "OnGoing",
+	"movesThisTurn": 1,
+	"requiresTieBreak": false
+}`
+
+func TestDecodeTransportState(t *testing.T) {
+	var tState TransportState
+	assert.Nil(t, json.Unmarshal([]byte(transportJson), &tState))
+	assert.Equal(t, []TransportRobots{
+		{Position: Pair{0, -4}, Robot: TransportRobot{Player: 1, Dir: Pair{0, 1}, IsBeamEnabled: true}},
+		{Position: Pair{4, 0}, Robot: TransportRobot{Player: 2, Dir: Pair{-1, 0}, IsLocked: true}},
+	}, tState.Robots)
+	assert.Equal(t, OnGoing, tState.Status)
+
+	state := StateFromTransport(&tState)
+	assert.Equal(t, -1, state.Winner)
+	assert.Equal(t, PlayerPosition(1), state.PlayerTurn)
+	assert.Equal(t, Robot{Position: Pair{4, 0}, Direction: W, IsLockedDown: true, Player: 1}, state.Robots[1])
+
+	b, err := json.Marshal(ConvertToTransport(state))
+	assert.Nil(t, err)
+	assert.JSONEq(t, transportJson, string(b))
+}
+
+func TestDecodeStatus(t *testing.T) {
+	testcases := []struct {
+		json   string
+		status Status
+		winner int
+		err    bool
+	}{
+		{`"OnGoing"`, OnGoing, -1, false},
+		{`"1"`, Status("1"), 1, false},
+		{`0`, Status("0"), 0, false},
+		{`"Over"`, "", 0, true},
+		{`"-1"`, "", 0, true},
+		{`1.5`, "", 0, true},
+		{`{"winner": 1}`, "", 0, true},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.json, func(t *testing.T) {
+			var status Status
+			err := json.Unmarshal([]byte(tc.json), &status)
+			if tc.err {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.status, status)
+			assert.Equal(t, tc.winner, status.Winner())
+		})
+	}
+}
+
+func TestDecodeTransportRobotsErrors(t *testing.T) {
+	testcases := []struct {
+		json string
+		err  string
+	}{
+		{`{"q": 0}`, "[position, robot] pair"},
+		{`[{"q": 0, "r": 0}]`, "has 1 elements"},
+		{`[{"q": 0}, {"player": 1, "dir": {"q": 0, "r": 1}}]`, "must have a q and r"},
+		{`["{0, 0}", {"player": 1, "dir": {"q": 0, "r": 1}}]`, "robot position"},
+		{`[{"q": 0, "r": 0}, {"dir": {"q": 0, "r": 1}}]`, "must have a player"},
+		{`[{"q": 0, "r": 0}, {"player": 0, "dir": {"q": 0, "r": 1}}]`, "must have a player"},
+		{`[{"q": 0, "r": 0}, {"player": 1}]`, "direction: missing"},
+		{`[{"q": 0, "r": 0}, {"player": "1", "dir": {"q": 0, "r": 1}}]`, "robot at {0, 0}"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.json, func(t *testing.T) {
+			var robot TransportRobots
+			err := json.Unmarshal([]byte(tc.json), &robot)
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func FuzzDecodeTransportState(f *testing.F) {
+	f.Add(transportJson)
+	f.Add(`{"robots": [[{"q": 0, "r": 0}, {"player": 1, "dir": {"q": 1, "r": 0}}]], "status": "0"}`)
+	f.Add(`{"robots": [[{"q": 0}, {}]], "status": 3}`)
+	f.Add(`{"robots": [null, [], [1, 2, 3]], "status": null}`)
+	f.Add(`{"robots": "none", "status": {"winner": 1}}`)
+	f.Fuzz(func(t *testing.T, data string) {
+		var tState TransportState
+		if err := json.Unmarshal([]byte(data), &tState); err != nil {
+			return
+		}
+		state := StateFromTransport(&tState)
+
+		// Decoded states survive a round trip.
+		b, err := json.Marshal(ConvertToTransport(state))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var again TransportState
+		if err := json.Unmarshal(b, &again); err != nil {
+			t.Fatalf("%s: %s", b, err)
+		}
+		assert.Equal(t, ConvertToTransport(state), ConvertToTransport(StateFromTransport(&again)))
+	})
+}
+
+func TestValidateTransportState(t *testing.T) {
+	var valid TransportState
+	assert.Nil(t, json.Unmarshal([]byte(transportJson), &valid))
+	assert.Nil(t, valid.Validate())
+	assert.Nil(t, ConvertToTransport(playRandomGame(t, 1, 30)).Validate())
+
+	robot := func(q, r, player int, dir Pair) TransportRobots {
+		return TransportRobots{Position: Pair{q, r}, Robot: TransportRobot{Player: player, Dir: dir}}
+	}
+	testCases := []struct {
+		name   string
+		modify func(s *TransportState)
+		err    string
+	}{
+		{"game def", func(s *TransportState) { s.GameDef.Board.HexaBoard.ArenaRadius = 0 }, "arena radius must be positive, is 0"},
+		{"players", func(s *TransportState) { s.Players = s.Players[:1] }, "game is for 2 players, has 1"},
+		{"player turn", func(s *TransportState) { s.PlayerTurn = 3 }, "player turn must be between 1 and 2, is 3"},
+		{"moves this turn", func(s *TransportState) { s.MovesThisTurn = 4 }, "moves this turn must be between 0 and 3, is 4"},
+		{"winner", func(s *TransportState) { s.Status = "2" }, "winner 2 isn't a player"},
+		{"placed robots", func(s *TransportState) { s.Players[1].PlacedRobots = 7 }, "player 2 placed 7 robots, of 6"},
+		{"off the board", func(s *TransportState) { s.Robots = append(s.Robots, robot(6, 0, 1, W)) }, "robot at {6, 0} is off the board"},
+		{"stacked", func(s *TransportState) { s.Robots = append(s.Robots, robot(0, -4, 2, W)) }, "two robots at {0, -4}"},
+		{"unknown player", func(s *TransportState) { s.Robots = append(s.Robots, robot(1, 1, 3, W)) }, "robot at {1, 1} belongs to unknown player 3"},
+		{"direction", func(s *TransportState) { s.Robots = append(s.Robots, robot(1, 1, 1, Pair{2, 0})) }, "robot at {1, 1} faces {2, 0}, which isn't a direction"},
+	}
+	for _, tc := range testCases {
+		var state TransportState
+		assert.Nil(t, json.Unmarshal([]byte(transportJson), &state))
+		tc.modify(&state)
+		assert.EqualError(t, state.Validate(), tc.err, tc.name)
+	}
+}
@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteTo(t *testing.T) {
+	registry := NewRegistry()
+	requests := registry.Counter("requests_total", "Requests served.", "path", "status")
+	latency := registry.Histogram("latency_seconds", "Time to serve.", []float64{0.1, 1})
+	registry.GaugeFunc("up", "Whether it's up.", func() float64 { return 1 })
+	registry.Counter("empty_total", "Never counted.")
+
+	requests.Inc("/b", "200")
+	requests.Add(2, "/a", "400")
+	requests.Inc("/a", "400")
+	requests.Inc(`/"quoted"`, "200")
+	latency.Observe(0.05)
+	latency.Observe(0.5)
+	latency.Observe(3)
+
+	var out strings.Builder
+	_, err := registry.WriteTo(&out)
+	assert.Nil(t, err)
+	assert.Equal(t, `# HELP requests_total Requests served.
+# TYPE requests_total counter
+requests_total{path="/\"quoted\"",status="200"} 1
+requests_total{path="/a",status="400"} 3
+requests_total{path="/b",status="200"} 1
+# HELP latency_seconds Time to serve.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{le="0.1"} 1
+latency_seconds_bucket{le="1"} 2
+latency_seconds_bucket{le="+Inf"} 3
+latency_seconds_sum 3.55
+latency_seconds_count 3
+# HELP up Whether it's up.
+# TYPE up gauge
+up 1
+# HELP empty_total Never counted.
+# TYPE empty_total counter
+`, out.String())
+}
+
+func TestServeHTTP(t *testing.T) {
+	registry := NewRegistry()
+	registry.Counter("moves_total", "Moves made.").Inc()
+
+	recorder := httptest.NewRecorder()
+	registry.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.Contains(t, recorder.Body.String(), "moves_total 1\n")
+}
+
+func TestConcurrentUpdates(t *testing.T) {
+	registry := NewRegistry()
+	counter := registry.Counter("events_total", "Events.", "kind")
+	histogram := registry.Histogram("sizes", "Sizes.", LinearBuckets(1, 1, 3), "kind")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				counter.Inc("a")
+				histogram.Observe(float64(j%4), "a")
+				registry.WriteTo(&strings.Builder{})
+			}
+		}()
+	}
+	wg.Wait()
+
+	var out strings.Builder
+	registry.WriteTo(&out)
+	assert.Contains(t, out.String(), `events_total{kind="a"} 800`)
+	assert.Contains(t, out.String(), `sizes_bucket{kind="a",le="1"} 400`)
+	assert.Contains(t, out.String(), `sizes_count{kind="a"} 800`)
+}
+
+func TestRegistryPanics(t *testing.T) {
+	registry := NewRegistry()
+	counter := registry.Counter("events_total", "Events.", "kind")
+	assert.Panics(t, func() { registry.Counter("events_total", "Again.") })
+	assert.Panics(t, func() { counter.Inc() })
+	assert.Panics(t, func() { counter.Inc("a", "b") })
+}
+
+func TestBuckets(t *testing.T) {
+	assert.Equal(t, []float64{1, 3, 5}, LinearBuckets(1, 2, 3))
+	assert.Equal(t, []float64{10, 100, 1000}, ExponentialBuckets(10, 10, 3))
+}
@@ -20,12 +20,13 @@ func absInt(val int) int {
 	return (val ^ y) - y
 }
 
-// Calculates the priority of the node as sum of distance to goal + path so far
+// Calculates the priority of the node as sum of distance to goal + path so far. Both are counted in pawn moves, and
+// each pawn move crosses two cells, so the distance to goal never overestimates and the path found is a shortest one.
 func (node *PQNode) setPriority(goal Position) {
 	if goal.Y < 0 {
-		node.priority = absInt(goal.X-node.position.X) + node.distance
+		node.priority = absInt(goal.X-node.position.X)/2 + node.distance
 	} else if goal.X < 0 {
-		node.priority = absInt(goal.Y-node.position.Y) + node.distance
+		node.priority = absInt(goal.Y-node.position.Y)/2 + node.distance
 	}
 }
 
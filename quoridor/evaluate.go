@@ -0,0 +1,70 @@
+package quoridor
+
+// WinScore is the score of a won game.
+const WinScore = 1_000_000
+
+// Evaluator scores a game for a player, higher is better for the player.
+type Evaluator func(game *Game, player PlayerPosition) int
+
+// Evaluators maps strategy names, as used by the scorer server, to their
+// Evaluator.
+var Evaluators = map[string]Evaluator{
+	"default": Evaluate,
+}
+
+// Evaluate scores a game for a player. The score is how many more pawn moves
+// the closest opponent needs to reach their goal than the player, plus how
+// many more barriers the player has left than that opponent.
+func Evaluate(game *Game, player PlayerPosition) int {
+	if game.IsOver() {
+		if game.Winner == player {
+			return WinScore
+		}
+		return -WinScore
+	}
+
+	closest := PlayerPosition(-1)
+	closestPath := 0
+	for position := range game.Players {
+		if position == player {
+			continue
+		}
+		path := game.PathLength(position)
+		if closest == -1 || path < closestPath || (path == closestPath && position < closest) {
+			closest, closestPath = position, path
+		}
+	}
+	if closest == -1 {
+		return 0
+	}
+	return closestPath - game.PathLength(player) +
+		game.Players[player].Barriers - game.Players[closest].Barriers
+}
+
+// PathLength is the fewest pawn moves a player needs to reach their goal.
+// Pawns are left out, they can move out of the way, so only barriers block
+// the path.
+func (game *Game) PathLength(player PlayerPosition) int {
+	return game.pathLength(game.Players[player].Pawn.Position, player)
+}
+
+// pathLength is the fewest pawn moves from the position to the player's
+// goal, leaving out the pawns.
+func (game *Game) pathLength(from Position, player PlayerPosition) int {
+	for _, p := range game.Players {
+		delete(game.Board, p.Pawn.Position)
+	}
+	defer func() {
+		for _, p := range game.Players {
+			game.Board[p.Pawn.Position] = p.Pawn
+		}
+	}()
+
+	path := game.FindPath(from, winningPositions[player])
+	if path == nil {
+		// Barriers can't block every path, so only a board built by hand
+		// gets here.
+		return BoardSize * BoardSize
+	}
+	return len(path)
+}
@@ -0,0 +1,82 @@
+package quoridor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Evaluate(t *testing.T) {
+	board :=
+		`....2............
+.................
+.................
+.................
+.................
+.................
+.................
+.................
+.................
+.................
+.................
+.................
+.................
+.................
+.................
+........---......
+........1........`
+	game, err := BuildQuoridorBoardFromString(board)
+	assert.NoError(t, err)
+	// The board reader numbers players by their digit.
+	game.Players = map[PlayerPosition]*Player{
+		PlayerOne: game.Players[1],
+		PlayerTwo: game.Players[2],
+	}
+
+	// The barrier makes player one go around, one extra move.
+	assert.Equal(t, 9, game.PathLength(PlayerOne))
+	assert.Equal(t, 8, game.PathLength(PlayerTwo))
+	assert.Equal(t, -1, Evaluate(game, PlayerOne))
+	assert.Equal(t, 1, Evaluate(game, PlayerTwo))
+
+	game.Players[PlayerOne].Barriers = 7
+	assert.Equal(t, 1, Evaluate(game, PlayerOne))
+	assert.Equal(t, -1, Evaluate(game, PlayerTwo))
+
+	game.EndDate = game.StartDate.AddDate(0, 0, 1)
+	game.Winner = PlayerTwo
+	assert.Equal(t, -WinScore, Evaluate(game, PlayerOne))
+	assert.Equal(t, WinScore, Evaluate(game, PlayerTwo))
+}
+
+func Test_PathLengthAroundWall(t *testing.T) {
+	// Heading straight for the goal leads the long way around the barriers,
+	// the shortest path starts with a step to the side.
+	board :=
+		`........2........
+........-------..
+.......|.........
+.......|.........
+.......|.........
+.......|.........
+.......|.........
+.......|.........
+.......|.........
+.......|.........
+.......|.........
+.......|.........
+.......|.........
+.................
+........1........
+.................
+.................`
+	game, err := BuildQuoridorBoardFromString(board)
+	assert.NoError(t, err)
+	game.Players = map[PlayerPosition]*Player{
+		PlayerOne: game.Players[1],
+		PlayerTwo: game.Players[2],
+	}
+
+	assert.Equal(t, 8, game.PathLength(PlayerOne))
+	assert.Len(t, game.FindPath(game.Players[PlayerOne].Pawn.Position, winningPositions[PlayerOne]), 8)
+}
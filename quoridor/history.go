@@ -0,0 +1,150 @@
+package quoridor
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Everything a move changes, so Undo can put it back.
+type historyEntry struct {
+	move Move
+
+	// Where the pawn moved from, for pawn moves.
+	pawnOrigin Position
+	// The cells of the barrier, and the player's barrier count before it was placed, for barrier moves.
+	barrierCells [3]Position
+	barriers     int
+
+	currentTurn PlayerPosition
+	winner      PlayerPosition
+	endDate     time.Time
+}
+
+// PawnMove is the move of the player's pawn to the position.
+func PawnMove(player PlayerPosition, position Position) Move {
+	return Move{Player: player, Delta: []Position{position}}
+}
+
+// BarrierMove is the placement of a barrier by the player at the position.
+func BarrierMove(player PlayerPosition, position Position) Move {
+	return Move{Player: player, Delta: []Position{position}}
+}
+
+// Position is where the pawn moves to, or the barrier is placed. The move must have a position, as the moves of
+// PawnMove, BarrierMove and LegalMoves do, Position panics on the zero Move.
+func (move Move) Position() Position {
+	return move.Delta[0]
+}
+
+// IsBarrier is true if the move places a barrier, false if it moves a pawn.
+func (move Move) IsBarrier() bool {
+	return !isValidPawnLocation(move.Position())
+}
+
+func (move Move) String() string {
+	if len(move.Delta) == 0 {
+		return "none"
+	}
+	kind := "pawn"
+	if move.IsBarrier() {
+		kind = "barrier"
+	}
+	position := move.Position()
+	return fmt.Sprintf("%s:%d,%d", kind, position.X, position.Y)
+}
+
+// Apply plays the move, as MovePawn or PlaceBarrier would. Returns an error if the move is invalid, in which case the
+// game is unchanged.
+func (game *Game) Apply(move Move) error {
+	if len(move.Delta) != 1 {
+		return errors.New(fmt.Sprintf("a move has exactly one position, got %d", len(move.Delta)))
+	}
+	if _, found := game.Players[move.Player]; !found {
+		return errors.New(fmt.Sprintf("there is no player %d in this game", move.Player))
+	}
+	if move.IsBarrier() {
+		return game.PlaceBarrier(move.Position(), move.Player)
+	}
+	return game.MovePawn(move.Position(), move.Player)
+}
+
+// Undo takes back the last move played, restoring the board, the barrier count, the turn and the winner. Returns an
+// error if no moves were played.
+func (game *Game) Undo() error {
+	if len(game.history) == 0 {
+		return errors.New("there are no moves to undo")
+	}
+	game.undone = append(game.undone, game.undo())
+	return nil
+}
+
+// undo takes back the last move, which must exist, without keeping it for Redo. Returns the move.
+func (game *Game) undo() Move {
+	entry := game.history[len(game.history)-1]
+	game.history = game.history[:len(game.history)-1]
+
+	player := game.Players[entry.move.Player]
+	if entry.move.IsBarrier() {
+		for _, cell := range entry.barrierCells {
+			delete(game.Board, cell)
+		}
+		player.Barriers = entry.barriers
+	} else {
+		delete(game.Board, player.Pawn.Position)
+		player.Pawn.Position = entry.pawnOrigin
+		game.Board[player.Pawn.Position] = player.Pawn
+	}
+	game.CurrentTurn = entry.currentTurn
+	game.Winner = entry.winner
+	game.EndDate = entry.endDate
+	return entry.move
+}
+
+// Redo plays the last move taken back by Undo again. Playing any other move forgets the undone moves. Returns an error
+// if there are no moves to redo.
+func (game *Game) Redo() error {
+	if len(game.undone) == 0 {
+		return errors.New("there are no moves to redo")
+	}
+	move := game.undone[len(game.undone)-1]
+	game.undone = game.undone[:len(game.undone)-1]
+	// The move was legal in this position before it was undone.
+	game.play(move)
+	return nil
+}
+
+// History is the moves played, in order.
+func (game *Game) History() []Move {
+	moves := make([]Move, len(game.history))
+	for i, entry := range game.history {
+		moves[i] = entry.move
+	}
+	return moves
+}
+
+// play plays a move without checking it.
+func (game *Game) play(move Move) {
+	if move.IsBarrier() {
+		game.placeBarrier(move.Position(), move.Player)
+	} else {
+		game.movePawn(move.Position(), move.Player)
+	}
+}
+
+// record saves what the move is about to change to the history.
+func (game *Game) record(move Move) {
+	player := game.Players[move.Player]
+	entry := historyEntry{
+		move:        move,
+		pawnOrigin:  player.Pawn.Position,
+		barriers:    player.Barriers,
+		currentTurn: game.CurrentTurn,
+		winner:      game.Winner,
+		endDate:     game.EndDate,
+	}
+	if move.IsBarrier() {
+		entry.barrierCells = createBarrierPositions(move.Position())
+	}
+	game.history = append(game.history, entry)
+}
@@ -0,0 +1,122 @@
+package quoridor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTwoPlayerGame(t *testing.T) *Game {
+	game, err := NewGame(TestIds[0], "history")
+	require.NoError(t, err)
+	_, err = game.AddPlayer(TestIds[1], "one")
+	require.NoError(t, err)
+	_, err = game.AddPlayer(TestIds[2], "two")
+	require.NoError(t, err)
+	require.NoError(t, game.StartGame())
+	return game
+}
+
+// putPawn moves the player's pawn, whatever the rules say.
+func putPawn(game *Game, player PlayerPosition, position Position) {
+	pawn := &game.Players[player].Pawn
+	delete(game.Board, pawn.Position)
+	pawn.Position = position
+	game.Board[position] = *pawn
+}
+
+func assertSameState(t *testing.T, expected Game, actual *Game) {
+	assert.Equal(t, expected.Board, actual.Board)
+	assert.Equal(t, expected.Players, actual.Players)
+	assert.Equal(t, expected.CurrentTurn, actual.CurrentTurn)
+	assert.Equal(t, expected.Winner, actual.Winner)
+	assert.Equal(t, expected.EndDate, actual.EndDate)
+}
+
+func Test_ApplyAndUndo(t *testing.T) {
+	game := newTwoPlayerGame(t)
+	start := game.Copy()
+
+	moves := []Move{
+		PawnMove(PlayerOne, Position{X: 8, Y: 14}),
+		BarrierMove(PlayerTwo, Position{X: 8, Y: 13}),
+		PawnMove(PlayerOne, Position{X: 6, Y: 14}),
+		BarrierMove(PlayerTwo, Position{X: 5, Y: 12}),
+	}
+	var states []Game
+	for _, move := range moves {
+		states = append(states, game.Copy())
+		require.NoError(t, game.Apply(move), move.String())
+	}
+	assert.Equal(t, moves, game.History())
+	assert.Equal(t, 8, game.Players[PlayerTwo].Barriers)
+	assert.Len(t, game.Board, 2+2*3)
+
+	for i := len(moves) - 1; i >= 0; i-- {
+		require.NoError(t, game.Undo())
+		assertSameState(t, states[i], game)
+	}
+	assertSameState(t, start, game)
+	assert.Empty(t, game.History())
+	assert.Error(t, game.Undo())
+}
+
+func Test_Redo(t *testing.T) {
+	game := newTwoPlayerGame(t)
+	require.NoError(t, game.Apply(PawnMove(PlayerOne, Position{X: 8, Y: 14})))
+	require.NoError(t, game.Apply(BarrierMove(PlayerTwo, Position{X: 8, Y: 13})))
+	end := game.Copy()
+
+	require.NoError(t, game.Undo())
+	require.NoError(t, game.Undo())
+	require.NoError(t, game.Redo())
+	require.NoError(t, game.Redo())
+	assertSameState(t, end, game)
+	assert.Error(t, game.Redo())
+
+	// Playing another move forgets the undone moves.
+	require.NoError(t, game.Undo())
+	require.NoError(t, game.Apply(PawnMove(PlayerTwo, Position{X: 8, Y: 2})))
+	assert.Error(t, game.Redo())
+
+	// An invalid move doesn't.
+	require.NoError(t, game.Undo())
+	assert.Error(t, game.Apply(PawnMove(PlayerTwo, Position{X: 8, Y: 6})))
+	assert.NoError(t, game.Redo())
+}
+
+func Test_UndoWin(t *testing.T) {
+	game := newTwoPlayerGame(t)
+	putPawn(game, PlayerOne, Position{X: 8, Y: 2})
+	putPawn(game, PlayerTwo, Position{X: 0, Y: 0})
+
+	require.NoError(t, game.Apply(PawnMove(PlayerOne, Position{X: 8, Y: 0})))
+	assert.True(t, game.IsOver())
+	assert.Equal(t, PlayerOne, game.Winner)
+	assert.Error(t, game.Apply(PawnMove(PlayerTwo, Position{X: 2, Y: 0})))
+
+	require.NoError(t, game.Undo())
+	assert.False(t, game.IsOver())
+	assert.Equal(t, PlayerPosition(-1), game.Winner)
+	assert.Equal(t, PlayerOne, game.CurrentTurn)
+	assert.Equal(t, Position{X: 8, Y: 2}, game.Players[PlayerOne].Pawn.Position)
+}
+
+func Test_ApplyErrors(t *testing.T) {
+	game := newTwoPlayerGame(t)
+	start := game.Copy()
+
+	assert.EqualError(t, game.Apply(Move{Player: PlayerOne}), "a move has exactly one position, got 0")
+	assert.EqualError(t, game.Apply(PawnMove(PlayerThree, Position{X: 0, Y: 8})), "there is no player 2 in this game")
+	assert.Error(t, game.Apply(PawnMove(PlayerTwo, Position{X: 8, Y: 2})))
+	assert.Error(t, game.Apply(BarrierMove(PlayerOne, Position{X: 16, Y: 15})))
+	assertSameState(t, start, game)
+	assert.Empty(t, game.History())
+}
+
+func Test_MoveString(t *testing.T) {
+	assert.Equal(t, "pawn:8,2", PawnMove(PlayerOne, Position{X: 8, Y: 2}).String())
+	assert.Equal(t, "barrier:8,3", BarrierMove(PlayerOne, Position{X: 8, Y: 3}).String())
+	assert.Equal(t, "none", Move{}.String())
+}
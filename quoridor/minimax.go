@@ -0,0 +1,154 @@
+package quoridor
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/rwsargent/boardbots-go/internal/minimax"
+)
+
+// MinimaxNode is a position in a search of the game tree, reached by its move.
+type MinimaxNode struct {
+	Game         *Game
+	GameMove     Move
+	Searcher     PlayerPosition
+	Evaluator    Evaluator
+	MinimaxValue int
+}
+
+var nodePool = sync.Pool{
+	New: func() any {
+		return &MinimaxNode{}
+	},
+}
+
+func (n *MinimaxNode) Evaluate() {
+	n.MinimaxValue = n.Evaluator(n.Game, n.Searcher)
+}
+
+// Children are the legal moves of the player to move, the most promising first.
+func (n *MinimaxNode) Children(nodeBuffer []minimax.Node) []minimax.Node {
+	moves := n.Game.LegalMoves(n.Game.CurrentTurn)
+	orderMoves(n.Game, moves)
+	for _, move := range moves {
+		node := nodePool.Get().(*MinimaxNode)
+		node.Game = n.Game
+		node.GameMove = move
+		node.Searcher = n.Searcher
+		node.Evaluator = n.Evaluator
+		nodeBuffer = append(nodeBuffer, node)
+	}
+	return nodeBuffer
+}
+
+func (n *MinimaxNode) ShouldMaximize() bool {
+	return n.Searcher == n.Game.CurrentTurn
+}
+
+// Move plays the node's move, which Children found legal, without checking it again.
+func (n *MinimaxNode) Move() {
+	n.Game.play(n.GameMove)
+}
+
+func (n *MinimaxNode) Undo() {
+	n.Game.undo()
+}
+
+func (n *MinimaxNode) Score() int {
+	return n.MinimaxValue
+}
+
+func (n *MinimaxNode) SetScore(score int) {
+	n.MinimaxValue = score
+}
+
+func (n *MinimaxNode) Release() {
+	nodePool.Put(n)
+}
+
+// BestMove searches depth moves ahead, with alpha-beta pruning, for the best move of the player to move. Returns the
+// move and its score for the player, or false if the game is over. The game is left as it was found.
+func BestMove(game *Game, depth int, evaluator Evaluator) (Move, int, bool) {
+	root := &MinimaxNode{
+		Game:      game,
+		Searcher:  game.CurrentTurn,
+		Evaluator: evaluator,
+	}
+	best := minimax.AlphaBeta(root, depth)
+	if best == minimax.Node(root) {
+		return Move{}, root.Score(), false
+	}
+	node := best.(*MinimaxNode)
+	move, score := node.GameMove, node.Score()
+	node.Release()
+	return move, score, true
+}
+
+// orderMoves sorts the moves of the player to move so alpha-beta prunes more. Moves are ranked by how many moves they
+// gain the player in the race to the goal: the barriers by how much longer they make the opponents' paths than the
+// player's, the pawn moves by how much shorter they make the player's path. Barriers go first when they gain as much
+// as a pawn move. Only barriers on an opponent's path can lengthen it, the others rank last.
+func orderMoves(game *Game, moves []Move) {
+	if len(moves) == 0 {
+		return
+	}
+	player := moves[0].Player
+	ownLength := game.PathLength(player)
+	opponentLengths := make(map[PlayerPosition]int)
+	opponentCells := make(map[Position]bool)
+	for position := range game.Players {
+		if position != player {
+			opponentLengths[position] = game.PathLength(position)
+			game.addPathCells(position, opponentCells)
+		}
+	}
+
+	// The gain of barriers off the opponents' paths.
+	const noGain = -BoardSize * BoardSize
+	gains := make([]int, len(moves))
+	for i, move := range moves {
+		if !move.IsBarrier() {
+			gains[i] = ownLength - game.pathLength(move.Position(), player)
+			continue
+		}
+		cells := createBarrierPositions(move.Position())
+		if !opponentCells[cells[0]] && !opponentCells[cells[1]] && !opponentCells[cells[2]] {
+			gains[i] = noGain
+			continue
+		}
+		for _, cell := range cells {
+			game.Board[cell] = Piece{Position: cell, Owner: player, Type: Barrier}
+		}
+		gain := ownLength - game.PathLength(player)
+		for position, length := range opponentLengths {
+			gain += game.PathLength(position) - length
+		}
+		for _, cell := range cells {
+			delete(game.Board, cell)
+		}
+		gains[i] = gain
+	}
+	sort.Stable(movesByGain{moves, gains})
+}
+
+// movesByGain sorts moves by their gains, the largest first, and barriers before pawn moves of the same gain.
+type movesByGain struct {
+	moves []Move
+	gains []int
+}
+
+func (m movesByGain) Len() int {
+	return len(m.moves)
+}
+
+func (m movesByGain) Less(i, j int) bool {
+	if m.gains[i] != m.gains[j] {
+		return m.gains[i] > m.gains[j]
+	}
+	return m.moves[i].IsBarrier() && !m.moves[j].IsBarrier()
+}
+
+func (m movesByGain) Swap(i, j int) {
+	m.moves[i], m.moves[j] = m.moves[j], m.moves[i]
+	m.gains[i], m.gains[j] = m.gains[j], m.gains[i]
+}
@@ -0,0 +1,90 @@
+package quoridor
+
+import (
+	"testing"
+
+	"github.com/rwsargent/boardbots-go/internal/minimax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Player two is a move from their goal, only a barrier in front of them stops it.
+const oneMoveFromGoal = `.................
+.................
+.................
+.................
+........1........
+.................
+.................
+.................
+.................
+.................
+.................
+.................
+.................
+.................
+........2........
+.................
+.................`
+
+func isBlockingBarrier(move Move) bool {
+	return move.IsBarrier() && move.Position().Y == 15 &&
+		(move.Position().X == 6 || move.Position().X == 8)
+}
+
+func Test_OrderMoves(t *testing.T) {
+	game := buildTwoPlayerBoard(t, oneMoveFromGoal)
+	moves := game.LegalMoves(PlayerOne)
+	ordered := append([]Move(nil), moves...)
+	orderMoves(game, ordered)
+
+	assert.ElementsMatch(t, moves, ordered)
+	// The barriers in front of player two make them go around, then comes the pawn move towards the goal.
+	assert.True(t, isBlockingBarrier(ordered[0]), ordered[0].String())
+	assert.True(t, isBlockingBarrier(ordered[1]), ordered[1].String())
+	assert.Equal(t, PawnMove(PlayerOne, Position{X: 8, Y: 2}), ordered[2])
+}
+
+func Test_BestMoveBlocks(t *testing.T) {
+	game := buildTwoPlayerBoard(t, oneMoveFromGoal)
+	before := game.Copy()
+
+	move, score, found := BestMove(game, 2, Evaluate)
+	require.True(t, found)
+	assert.True(t, isBlockingBarrier(move), move.String())
+	assert.Greater(t, score, -WinScore)
+	assertSameState(t, before, game)
+	assert.Empty(t, game.History())
+}
+
+func Test_BestMoveWins(t *testing.T) {
+	game := newTwoPlayerGame(t)
+	putPawn(game, PlayerOne, Position{X: 8, Y: 2})
+	putPawn(game, PlayerTwo, Position{X: 0, Y: 0})
+
+	move, score, found := BestMove(game, 3, Evaluate)
+	require.True(t, found)
+	assert.Equal(t, PawnMove(PlayerOne, Position{X: 8, Y: 0}), move)
+	assert.Equal(t, WinScore, score)
+
+	require.NoError(t, game.Apply(move))
+	_, _, found = BestMove(game, 3, Evaluate)
+	assert.False(t, found)
+}
+
+func Test_AlphaBetaMatchesMinimax(t *testing.T) {
+	game := newTwoPlayerGame(t)
+	require.NoError(t, game.Apply(BarrierMove(PlayerOne, Position{X: 8, Y: 3})))
+	require.NoError(t, game.Apply(BarrierMove(PlayerTwo, Position{X: 9, Y: 12})))
+	// Without barriers left only pawns move, few enough moves for a plain minimax.
+	game.Players[PlayerOne].Barriers = 0
+	game.Players[PlayerTwo].Barriers = 0
+
+	for depth := 1; depth <= 4; depth++ {
+		root := &MinimaxNode{Game: game, Searcher: game.CurrentTurn, Evaluator: Evaluate}
+		expected := minimax.Minimax(root, depth).Score()
+		_, score, found := BestMove(game, depth, Evaluate)
+		require.True(t, found)
+		assert.Equal(t, expected, score, "depth %d", depth)
+	}
+}
@@ -0,0 +1,98 @@
+package quoridor
+
+// The number of barrier positions on an empty board, eight rows and eight columns of eight.
+const barrierPositions = 2 * 8 * 8
+
+// LegalMoves returns every move the player can make on their turn: the pawn moves, then every barrier they can place,
+// row by row. Barriers can't overlap or cross another barrier, and can't take away a player's last path to their
+// goal. Returns nil if the game is over, or the player isn't in the game.
+func (game *Game) LegalMoves(player PlayerPosition) []Move {
+	p, found := game.Players[player]
+	if !found || game.IsOver() {
+		return nil
+	}
+	pawnMoves := game.Board.GetValidPawnMoves(p.Pawn.Position)
+	moves := make([]Move, 0, len(pawnMoves)+barrierPositions)
+	for _, position := range pawnMoves {
+		moves = append(moves, PawnMove(player, position))
+	}
+	if playerHasNoMoreBarriers(p) {
+		return moves
+	}
+	paths := game.pathCells()
+	for y := 0; y < BoardSize-1; y++ {
+		for x := 0; x < BoardSize-1; x++ {
+			position := Position{X: x, Y: y}
+			if game.canPlaceBarrier(position, paths) {
+				moves = append(moves, BarrierMove(player, position))
+			}
+		}
+	}
+	return moves
+}
+
+// canPlaceBarrier checks a barrier can be placed at the position, whoever places it. Only barriers covering one of
+// the paths cells can prevent a win, a nil paths checks every barrier.
+func (game *Game) canPlaceBarrier(position Position, paths map[Position]bool) bool {
+	if invalidPosition(position) {
+		return false
+	}
+	cells := createBarrierPositions(position)
+	if barriersAreInTheWay(cells, game.Board) {
+		return false
+	}
+	if paths != nil && !paths[cells[0]] && !paths[cells[1]] && !paths[cells[2]] {
+		// Every player keeps their path.
+		return true
+	}
+	return !barrierPreventsWin(cells, game)
+}
+
+// pathCells finds a path to the goal for every player, and returns the cells between the pawn positions of the paths.
+// A barrier on none of them leaves every path open. Returns nil if a player has no path.
+func (game *Game) pathCells() map[Position]bool {
+	cells := make(map[Position]bool)
+	for position := range game.Players {
+		if !game.addPathCells(position, cells) {
+			return nil
+		}
+	}
+	return cells
+}
+
+// addPathCells adds the cells between the pawn positions of a path of the player to their goal. Returns false if the
+// player has no path.
+func (game *Game) addPathCells(player PlayerPosition, cells map[Position]bool) bool {
+	from := game.Players[player].Pawn.Position
+	path := game.FindPath(from, winningPositions[player])
+	if path == nil {
+		return false
+	}
+	for _, to := range path {
+		// Steps are to a neighbour, a jump over a pawn, or diagonally past one. The cells with an odd row or column
+		// between them cover all three.
+		for y := minInt(from.Y, to.Y); y <= maxInt(from.Y, to.Y); y++ {
+			for x := minInt(from.X, to.X); x <= maxInt(from.X, to.X); x++ {
+				if x&0x1 == 1 || y&0x1 == 1 {
+					cells[Position{X: x, Y: y}] = true
+				}
+			}
+		}
+		from = to
+	}
+	return true
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
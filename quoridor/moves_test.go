@@ -0,0 +1,181 @@
+package quoridor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildTwoPlayerBoard builds a board with players 1 and 2 as PlayerOne and PlayerTwo.
+func buildTwoPlayerBoard(t *testing.T, board string) *Game {
+	game, err := BuildQuoridorBoardFromString(board)
+	require.NoError(t, err)
+	// The board reader numbers players by their digit.
+	game.Players = map[PlayerPosition]*Player{
+		PlayerOne: game.Players[1],
+		PlayerTwo: game.Players[2],
+	}
+	return game
+}
+
+func countMoves(moves []Move) (pawns, barriers int) {
+	for _, move := range moves {
+		if move.IsBarrier() {
+			barriers++
+		} else {
+			pawns++
+		}
+	}
+	return pawns, barriers
+}
+
+func Test_LegalMoves(t *testing.T) {
+	var testCases = []struct {
+		name             string
+		board            string
+		pawns, barriers  int
+		preventedBarrier *Position
+	}{
+		{
+			// Up, left and right, and every barrier.
+			name: "start",
+			board: `........2........
+.................
+.................
+.................
+.................
+.................
+.................
+.................
+.................
+.................
+.................
+.................
+.................
+.................
+.................
+.................
+........1........`,
+			pawns: 3, barriers: 128,
+		},
+		{
+			// The barrier blocks moving up. It overlaps three horizontal barriers, and one vertical barrier
+			// crosses it.
+			name: "barrier in front",
+			board: `........2........
+.................
+.................
+.................
+.................
+.................
+.................
+.................
+.................
+.................
+.................
+.................
+.................
+.................
+.................
+........---......
+........1........`,
+			pawns: 2, barriers: 124,
+		},
+		{
+			// Left, right, down, and jumping over player two.
+			name: "face to face",
+			board: `.................
+.................
+.................
+.................
+.................
+.................
+.................
+.................
+........2........
+.................
+........1........
+.................
+.................
+.................
+.................
+.................
+.................`,
+			pawns: 4, barriers: 128,
+		},
+		{
+			// Player one can only leave the corner going up. The vertical barrier overlaps two vertical barriers,
+			// one horizontal barrier crosses it, and the barrier above the corner would shut player one in.
+			name: "corner",
+			board: `........2........
+.................
+.................
+.................
+.................
+.................
+.................
+.................
+.................
+.................
+.................
+.................
+.................
+.................
+.|...............
+.|...............
+1|...............`,
+			pawns: 1, barriers: 124,
+			preventedBarrier: &Position{X: 0, Y: 13},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			game := buildTwoPlayerBoard(t, tc.board)
+			moves := game.LegalMoves(PlayerOne)
+			pawns, barriers := countMoves(moves)
+			assert.Equal(t, tc.pawns, pawns)
+			assert.Equal(t, tc.barriers, barriers)
+
+			for _, move := range moves {
+				assert.Equal(t, PlayerOne, move.Player)
+				game.CurrentTurn = PlayerOne
+				assert.NoError(t, game.Apply(move), move.String())
+				require.NoError(t, game.Undo())
+			}
+			// Checking only the barriers on the paths finds the same barriers as checking them all.
+			paths := game.pathCells()
+			for y := 0; y < BoardSize; y++ {
+				for x := 0; x < BoardSize; x++ {
+					position := Position{X: x, Y: y}
+					assert.Equal(t, game.canPlaceBarrier(position, nil), game.canPlaceBarrier(position, paths), position)
+				}
+			}
+			if tc.preventedBarrier != nil {
+				assert.NotContains(t, moves, BarrierMove(PlayerOne, *tc.preventedBarrier))
+				assert.EqualError(t, game.PlaceBarrier(*tc.preventedBarrier, PlayerOne), "the barrier prevents a players victory")
+			}
+		})
+	}
+}
+
+func Test_LegalMovesNoBarriersLeft(t *testing.T) {
+	game := newTwoPlayerGame(t)
+	game.Players[PlayerOne].Barriers = 0
+	moves := game.LegalMoves(PlayerOne)
+	assert.Equal(t, []Move{
+		PawnMove(PlayerOne, Position{X: 10, Y: 16}),
+		PawnMove(PlayerOne, Position{X: 6, Y: 16}),
+		PawnMove(PlayerOne, Position{X: 8, Y: 14}),
+	}, moves)
+}
+
+func Test_LegalMovesGameOver(t *testing.T) {
+	game := newTwoPlayerGame(t)
+	assert.Nil(t, game.LegalMoves(PlayerThree))
+
+	putPawn(game, PlayerOne, Position{X: 8, Y: 2})
+	putPawn(game, PlayerTwo, Position{X: 0, Y: 0})
+	require.NoError(t, game.Apply(PawnMove(PlayerOne, Position{X: 8, Y: 0})))
+	assert.Nil(t, game.LegalMoves(PlayerTwo))
+}
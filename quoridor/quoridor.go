@@ -48,6 +48,10 @@ type (
 		StartDate, EndDate time.Time
 		Winner             PlayerPosition
 		Name               string
+
+		// The moves played, and the moves undone since, for Undo and Redo.
+		history []historyEntry
+		undone  []Move
 	}
 
 	Piece struct {
@@ -56,6 +60,8 @@ type (
 		Type     TypeId
 	}
 
+	// A move of a player. Delta holds the single position the pawn moves to, or the position a barrier is placed
+	// at. Pawns move to positions with an even row and column, barriers have an odd row or column.
 	Move struct {
 		Player PlayerPosition
 		Delta  []Position
@@ -200,12 +206,20 @@ func (game *Game) MovePawn(newPosition Position, player PlayerPosition) error {
 	if game.IsOver() {
 		return errors.New("invalid move, game is already over")
 	}
+	game.undone = nil
+	game.movePawn(newPosition, player)
+	return nil
+}
+
+// movePawn moves the pawn without checking the move, and records it in the history.
+func (game *Game) movePawn(newPosition Position, player PlayerPosition) {
+	pawn := &game.Players[player].Pawn
+	game.record(PawnMove(player, newPosition))
 	delete(game.Board, pawn.Position)
 	pawn.Position = newPosition
 	game.Board[pawn.Position] = *pawn
 	checkGameOver(game)
 	game.nextTurn()
-	return nil
 }
 
 // GetValidMoveByDirection returns all possible valid positions a pawn can land in a given direction.
@@ -305,12 +319,19 @@ func (game *Game) PlaceBarrier(position Position, player PlayerPosition) error {
 	if game.IsOver() {
 		return errors.New("invalid move, game is already over")
 	}
+	game.undone = nil
+	game.placeBarrier(position, player)
+	return nil
+}
+
+// placeBarrier places the barrier without checking it, and records it in the history.
+func (game *Game) placeBarrier(position Position, player PlayerPosition) {
+	game.record(BarrierMove(player, position))
 	game.Players[player].Barriers--
-	for _, pos := range barrierPositions {
+	for _, pos := range createBarrierPositions(position) {
 		game.Board[pos] = Piece{Position: pos, Owner: player, Type: Barrier}
 	}
 	game.nextTurn()
-	return nil
 }
 
 // You can never place a pawn or barrier at a double-odd position (the intersections of the gutters), or on the very
@@ -412,7 +433,7 @@ func (game *Game) MaybeReturnWinnerPlayerPosition() PlayerPosition {
 	return -1
 }
 
-// Deep copy of a game. Will copy all Players, Pieces, and Board.
+// Deep copy of a game. Will copy all Players, Pieces, Board, and the move history.
 func (game *Game) Copy() Game {
 	newGame := Game{
 		Id:          game.Id,
@@ -438,6 +459,8 @@ func (game *Game) Copy() Game {
 			PlayerName: player.PlayerName,
 		}
 	}
+	newGame.history = append([]historyEntry(nil), game.history...)
+	newGame.undone = append([]Move(nil), game.undone...)
 	return newGame
 }
 
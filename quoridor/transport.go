@@ -0,0 +1,132 @@
+package quoridor
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// transport.go converts games to and from JSON, for clients like the scorer
+// server. Barriers are the positions they were placed at, as given to
+// PlaceBarrier, rather than the board cells they cover, and the player who
+// placed them. Players are counted from 0, as in Game.
+
+type (
+	TransportState struct {
+		// Players are indexed by their PlayerPosition.
+		Players     []TransportPlayer  `json:"players"`
+		Barriers    []TransportBarrier `json:"barriers"`
+		CurrentTurn PlayerPosition     `json:"currentTurn"`
+	}
+
+	TransportBarrier struct {
+		Position Position       `json:"position"`
+		Owner    PlayerPosition `json:"owner"`
+	}
+
+	TransportPlayer struct {
+		Pawn Position `json:"pawn"`
+		// Barriers is the number of barriers the player has left.
+		Barriers int `json:"barriers"`
+	}
+)
+
+// ConvertToTransport converts a game to its transport format.
+func ConvertToTransport(game *Game) *TransportState {
+	state := &TransportState{
+		Players:     make([]TransportPlayer, len(game.Players)),
+		Barriers:    barrierPlacements(game.Board),
+		CurrentTurn: game.CurrentTurn,
+	}
+	for position, player := range game.Players {
+		state.Players[position] = TransportPlayer{
+			Pawn:     player.Pawn.Position,
+			Barriers: player.Barriers,
+		}
+	}
+	return state
+}
+
+// StateFromTransport builds a game from its transport format. Returns an
+// error if the pieces couldn't be on a board together.
+func StateFromTransport(state *TransportState) (*Game, error) {
+	if !(len(state.Players) == 2 || len(state.Players) == 4) {
+		return nil, fmt.Errorf("wrong number of players (%d)", len(state.Players))
+	}
+	if state.CurrentTurn < 0 || int(state.CurrentTurn) >= len(state.Players) {
+		return nil, fmt.Errorf("current turn is for unknown player %d", state.CurrentTurn)
+	}
+	game := &Game{
+		Board:       make(Board),
+		Players:     make(map[PlayerPosition]*Player),
+		CurrentTurn: state.CurrentTurn,
+		Winner:      -1,
+	}
+	for i, player := range state.Players {
+		position := PlayerPosition(i)
+		if !isOnBoard(player.Pawn) || !isValidPawnLocation(player.Pawn) {
+			return nil, fmt.Errorf("invalid pawn position for player %d at %v", position, player.Pawn)
+		}
+		if _, taken := game.Board[player.Pawn]; taken {
+			return nil, fmt.Errorf("two pawns at %v", player.Pawn)
+		}
+		if player.Barriers < 0 {
+			return nil, fmt.Errorf("player %d has %d barriers", position, player.Barriers)
+		}
+		pawn := Piece{Position: player.Pawn, Owner: position, Type: Pawn}
+		game.Players[position] = &Player{
+			Barriers: player.Barriers,
+			Pawn:     pawn,
+		}
+		game.Board[pawn.Position] = pawn
+	}
+	for _, barrier := range state.Barriers {
+		if invalidPosition(barrier.Position) {
+			return nil, fmt.Errorf("invalid location for a barrier at %v", barrier.Position)
+		}
+		if _, found := game.Players[barrier.Owner]; !found {
+			return nil, fmt.Errorf("the barrier at %v belongs to unknown player %d", barrier.Position, barrier.Owner)
+		}
+		positions := createBarrierPositions(barrier.Position)
+		if barriersAreInTheWay(positions, game.Board) {
+			return nil, errors.New(fmt.Sprintf("the barrier at %v intersects with another", barrier.Position))
+		}
+		for _, pos := range positions {
+			game.Board[pos] = Piece{Position: pos, Owner: barrier.Owner, Type: Barrier}
+		}
+	}
+	checkGameOver(game)
+	return game, nil
+}
+
+// barrierPlacements finds the positions the barriers on the board were placed
+// at, and who placed them. The cells between pawn cells only belong to one kind of barrier, and
+// each barrier covers two of them, so they're paired up from the top left.
+func barrierPlacements(board Board) []TransportBarrier {
+	placements := make([]Position, 0)
+	for pos := range board {
+		if isABarrierRow(pos) || isABarrierColumn(pos) {
+			placements = append(placements, pos)
+		}
+	}
+	sort.Slice(placements, func(i, j int) bool {
+		if placements[i].Y != placements[j].Y {
+			return placements[i].Y < placements[j].Y
+		}
+		return placements[i].X < placements[j].X
+	})
+
+	covered := make(map[Position]bool)
+	barriers := make([]TransportBarrier, 0, len(placements)/2)
+	for _, pos := range placements {
+		if covered[pos] {
+			continue
+		}
+		barrier := createBarrierPositions(pos)
+		for _, cell := range barrier {
+			covered[cell] = true
+		}
+		barriers = append(barriers, TransportBarrier{Position: pos, Owner: board[pos].Owner})
+	}
+	return barriers
+}
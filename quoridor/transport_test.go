@@ -0,0 +1,96 @@
+package quoridor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_TransportRoundTrip(t *testing.T) {
+	game := NewGameWithPlayers(2)
+	assert.NoError(t, game.PlaceBarrier(Position{X: 8, Y: 13}, PlayerOne))
+	assert.NoError(t, game.PlaceBarrier(Position{X: 3, Y: 0}, PlayerTwo))
+	assert.NoError(t, game.PlaceBarrier(Position{X: 12, Y: 13}, PlayerOne)) // next to the first
+	assert.NoError(t, game.PlaceBarrier(Position{X: 11, Y: 12}, PlayerTwo)) // crossing the gap between them
+	assert.NoError(t, game.MovePawn(Position{X: 6, Y: 16}, PlayerOne))
+
+	state := ConvertToTransport(game)
+	assert.ElementsMatch(t, []TransportBarrier{
+		{Position: Position{X: 3, Y: 0}, Owner: PlayerTwo},
+		{Position: Position{X: 11, Y: 12}, Owner: PlayerTwo},
+		{Position: Position{X: 8, Y: 13}, Owner: PlayerOne},
+		{Position: Position{X: 12, Y: 13}, Owner: PlayerOne},
+	}, state.Barriers)
+	assert.Equal(t, []TransportPlayer{
+		{Pawn: Position{X: 6, Y: 16}, Barriers: 8},
+		{Pawn: Position{X: 8, Y: 0}, Barriers: 8},
+	}, state.Players)
+	assert.Equal(t, PlayerTwo, state.CurrentTurn)
+
+	loaded, err := StateFromTransport(state)
+	assert.NoError(t, err)
+	assert.Equal(t, game.Board, loaded.Board)
+	assert.Equal(t, PlayerTwo, loaded.CurrentTurn)
+	assert.Equal(t, PlayerPosition(-1), loaded.Winner)
+	assert.Equal(t, state, ConvertToTransport(loaded))
+}
+
+func Test_TransportWinner(t *testing.T) {
+	game, err := StateFromTransport(&TransportState{
+		Players: []TransportPlayer{{Pawn: Position{X: 4, Y: 0}}, {Pawn: Position{X: 8, Y: 6}}},
+	})
+	assert.NoError(t, err)
+	assert.True(t, game.IsOver())
+	assert.Equal(t, PlayerOne, game.Winner)
+}
+
+func Test_TransportErrors(t *testing.T) {
+	twoPlayers := []TransportPlayer{{Pawn: Position{X: 8, Y: 16}}, {Pawn: Position{X: 8, Y: 0}}}
+	var testCases = []struct {
+		name  string
+		state TransportState
+		err   string
+	}{
+		{"no players", TransportState{}, "wrong number of players (0)"},
+		{"unknown turn", TransportState{Players: twoPlayers, CurrentTurn: 2}, "current turn is for unknown player 2"},
+		{
+			"pawn off the board",
+			TransportState{Players: []TransportPlayer{{Pawn: Position{X: 8, Y: 18}}, {Pawn: Position{X: 8, Y: 0}}}},
+			"invalid pawn position for player 0 at {8 18}",
+		},
+		{
+			"pawn in a gutter",
+			TransportState{Players: []TransportPlayer{{Pawn: Position{X: 8, Y: 16}}, {Pawn: Position{X: 7, Y: 0}}}},
+			"invalid pawn position for player 1 at {7 0}",
+		},
+		{
+			"stacked pawns",
+			TransportState{Players: []TransportPlayer{{Pawn: Position{X: 8, Y: 8}}, {Pawn: Position{X: 8, Y: 8}}}},
+			"two pawns at {8 8}",
+		},
+		{
+			"negative barriers",
+			TransportState{Players: []TransportPlayer{{Pawn: Position{X: 8, Y: 16}}, {Pawn: Position{X: 8, Y: 0}, Barriers: -1}}},
+			"player 1 has -1 barriers",
+		},
+		{
+			"barrier on a pawn cell",
+			TransportState{Players: twoPlayers, Barriers: []TransportBarrier{{Position: Position{X: 2, Y: 2}}}},
+			"invalid location for a barrier at {2 2}",
+		},
+		{
+			"barrier of nobody",
+			TransportState{Players: twoPlayers, Barriers: []TransportBarrier{{Position: Position{X: 8, Y: 13}, Owner: 2}}},
+			"the barrier at {8 13} belongs to unknown player 2",
+		},
+		{
+			"crossing barriers",
+			TransportState{Players: twoPlayers, Barriers: []TransportBarrier{{Position: Position{X: 8, Y: 13}}, {Position: Position{X: 9, Y: 12}}}},
+			"the barrier at {9 12} intersects with another",
+		},
+	}
+	for _, tc := range testCases {
+		_, err := StateFromTransport(&tc.state)
+		assert.EqualError(t, err, tc.err, tc.name)
+	}
+}
@@ -0,0 +1,315 @@
+// Package rulecheck compares the rules of the local lockitdown engine with a
+// boardbots server. At every position it checks the engine generates the
+// same moves as the server's potential moves, and that applying a move
+// locally gives the same state as the server.
+package rulecheck
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rwsargent/boardbots-go/bot"
+	"github.com/rwsargent/boardbots-go/client"
+	"github.com/rwsargent/boardbots-go/lockitdown"
+)
+
+type (
+	// Kind is the kind of disagreement between the engine and the server.
+	Kind string
+
+	// Divergence is a disagreement between the engine and the server.
+	Divergence struct {
+		// Move is the number of moves made before the position.
+		Move     int
+		Kind     Kind
+		Detail   string
+		Position string
+	}
+
+	// checker follows a game, keeping the engine's state of it.
+	checker struct {
+		bbClient *client.BoardBotClient[lockitdown.TransportState]
+		gameId   string
+		local    *lockitdown.GameState
+		numMoves int
+	}
+)
+
+const (
+	// ServerOnlyMove is a move the server allows, and the engine doesn't.
+	ServerOnlyMove Kind = "server only move"
+	// EngineOnlyMove is a move the engine allows, and the server doesn't.
+	EngineOnlyMove Kind = "engine only move"
+	// RejectedMove is a move made on the server that the engine can't make.
+	RejectedMove Kind = "rejected move"
+	// StateMismatch is a move that leaves the engine and server in different
+	// states.
+	StateMismatch Kind = "state mismatch"
+)
+
+func (d Divergence) String() string {
+	return fmt.Sprintf("move %d: %s: %s\n%s", d.Move, d.Kind, d.Detail, d.Position)
+}
+
+// Check replays the moves of a game with the engine, and compares the
+// result with the server's current state and potential moves. Recorded
+// games only have the state after the last move, earlier positions are
+// only checked for the legality of the move made.
+func Check(ctx context.Context, bbClient *client.BoardBotClient[lockitdown.TransportState], gameId string) ([]Divergence, error) {
+	game, err := bbClient.Game(ctx, gameId)
+	if err != nil {
+		return nil, err
+	}
+	history, err := bbClient.MoveHistory(ctx, gameId)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &checker{
+		bbClient: bbClient,
+		gameId:   gameId,
+		local:    lockitdown.NewGame(game.State.GameDef),
+	}
+	divergences, replayed := c.replay(history)
+	if !replayed {
+		return divergences, nil
+	}
+	compared, err := c.compare(ctx, game)
+	return append(divergences, compared...), err
+}
+
+// Watch checks each position of a live game as the moves are made, until
+// the game is over or the context is cancelled. Each divergence is
+// reported as soon as it's found, then the engine continues from the
+// server's state.
+func Watch(ctx context.Context, bbClient *client.BoardBotClient[lockitdown.TransportState], gameId string, report func(Divergence)) error {
+	c := &checker{
+		bbClient: bbClient,
+		gameId:   gameId,
+		numMoves: -1,
+	}
+
+	subscription, unsubscribe := context.WithCancel(ctx)
+	defer unsubscribe()
+	for update := range bbClient.Subscribe(subscription, gameId) {
+		if update.Err != nil {
+			return update.Err
+		}
+		game := update.Game
+		if game.NumMoves <= c.numMoves {
+			continue
+		}
+
+		if c.local != nil {
+			history, err := bbClient.MoveHistory(ctx, gameId)
+			if err != nil {
+				return err
+			}
+			divergences, _ := c.replay(history)
+			for _, divergence := range divergences {
+				report(divergence)
+			}
+		}
+		divergences, err := c.compare(ctx, game)
+		if err != nil {
+			return err
+		}
+		for _, divergence := range divergences {
+			report(divergence)
+		}
+		if bot.LockItDown.Finished(game.State) {
+			return nil
+		}
+	}
+	return ctx.Err()
+}
+
+// replay makes the moves the engine hasn't seen yet. Returns false if a
+// move couldn't be made, and the engine's state can't be trusted.
+func (c *checker) replay(history []client.MoveResp) ([]Divergence, bool) {
+	var divergences []Divergence
+	for _, record := range history {
+		if record.Index < c.numMoves {
+			continue
+		}
+		position := c.local.Render()
+		diverge := func(kind Kind, detail string) {
+			divergences = append(divergences, Divergence{
+				Move:     record.Index,
+				Kind:     kind,
+				Detail:   detail,
+				Position: position,
+			})
+		}
+
+		move, err := bot.LockItDownGameMove(record.Move)
+		if err != nil {
+			diverge(RejectedMove, err.Error())
+			return divergences, false
+		}
+		if !contains(c.local.PossibleMoves(nil), move) {
+			diverge(ServerOnlyMove, moveKey(move))
+		}
+		if err := c.local.Move(&move); err != nil && c.local.Winner < 0 {
+			diverge(RejectedMove, fmt.Sprintf("%s: %s", moveKey(move), err))
+			return divergences, false
+		}
+		c.numMoves = record.Index + 1
+	}
+	return divergences, true
+}
+
+// compare compares the engine with the server's game, then continues from
+// the server's state.
+func (c *checker) compare(ctx context.Context, game client.Game[lockitdown.TransportState]) ([]Divergence, error) {
+	server := lockitdown.StateFromTransport(&game.State)
+
+	var divergences []Divergence
+	if c.local != nil && c.numMoves == game.NumMoves {
+		if differences := diffStates(c.local, server); len(differences) > 0 {
+			divergences = append(divergences, Divergence{
+				Move:     game.NumMoves,
+				Kind:     StateMismatch,
+				Detail:   strings.Join(differences, "; "),
+				Position: "engine:\n" + c.local.Render() + "server:\n" + server.Render(),
+			})
+		}
+	}
+	c.local = server
+	c.numMoves = game.NumMoves
+
+	serverMoves, err := c.bbClient.GetPossibleMoves(ctx, c.gameId)
+	if err != nil {
+		return divergences, err
+	}
+	// The moves are only comparable if no moves were made in the meantime.
+	current, err := c.bbClient.Game(ctx, c.gameId)
+	if err != nil {
+		return divergences, err
+	}
+	if current.NumMoves != game.NumMoves {
+		return divergences, nil
+	}
+	return append(divergences, compareMoves(server, game.NumMoves, serverMoves)...), nil
+}
+
+// compareMoves compares the moves the engine generates for a state with the
+// moves the server allows.
+func compareMoves(state *lockitdown.GameState, numMoves int, serverMoves []client.MoveT) []Divergence {
+	position := state.Render()
+	var divergences []Divergence
+	diverge := func(kind Kind, detail string) {
+		divergences = append(divergences, Divergence{
+			Move:     numMoves,
+			Kind:     kind,
+			Detail:   detail,
+			Position: position,
+		})
+	}
+
+	engine := make(map[string]bool)
+	for _, move := range state.PossibleMoves(nil) {
+		engine[moveKey(move)] = true
+	}
+	server := make(map[string]bool)
+	for _, serverMove := range serverMoves {
+		move, err := bot.LockItDownGameMove(serverMove)
+		if err != nil {
+			diverge(ServerOnlyMove, err.Error())
+			continue
+		}
+		server[moveKey(move)] = true
+	}
+
+	for _, key := range sortedKeys(server) {
+		if !engine[key] {
+			diverge(ServerOnlyMove, key)
+		}
+	}
+	for _, key := range sortedKeys(engine) {
+		if !server[key] {
+			diverge(EngineOnlyMove, key)
+		}
+	}
+	return divergences
+}
+
+// diffStates describes the differences between two states.
+func diffStates(local, server *lockitdown.GameState) []string {
+	var differences []string
+	differ := func(what string, local, server any) {
+		if local != server {
+			differences = append(differences, fmt.Sprintf("%s: engine %v, server %v", what, local, server))
+		}
+	}
+
+	differ("player turn", local.PlayerTurn+1, server.PlayerTurn+1)
+	differ("moves this turn", local.MovesThisTurn, server.MovesThisTurn)
+	differ("winner", local.Winner+1, server.Winner+1)
+	differ("requires tie break", local.RequiresTieBreak, server.RequiresTieBreak)
+	for i := 0; i < len(local.Players) && i < len(server.Players); i++ {
+		differ(fmt.Sprintf("player %d points", i+1), local.Players[i].Points, server.Players[i].Points)
+		differ(fmt.Sprintf("player %d placed robots", i+1), local.Players[i].PlacedRobots, server.Players[i].PlacedRobots)
+	}
+
+	robots := make(map[lockitdown.Pair]bool)
+	for _, robot := range local.Robots {
+		robots[robot.Position] = true
+	}
+	for _, robot := range server.Robots {
+		robots[robot.Position] = true
+	}
+	positions := make([]lockitdown.Pair, 0, len(robots))
+	for position := range robots {
+		positions = append(positions, position)
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		if positions[i].R != positions[j].R {
+			return positions[i].R < positions[j].R
+		}
+		return positions[i].Q < positions[j].Q
+	})
+	for _, position := range positions {
+		differ("robot at "+position.String(), describeRobot(local.RobotAt(position)), describeRobot(server.RobotAt(position)))
+	}
+	return differences
+}
+
+func describeRobot(robot *lockitdown.Robot) string {
+	if robot == nil {
+		return "none"
+	}
+	description := fmt.Sprintf("player %d facing %s", robot.Player+1, robot.Direction)
+	if robot.IsLockedDown {
+		description += ", locked down"
+	}
+	if !robot.IsBeamEnabled {
+		description += ", beam disabled"
+	}
+	return description
+}
+
+func moveKey(move lockitdown.GameMove) string {
+	return fmt.Sprintf("player %d %s", move.Player+1, move.Mover)
+}
+
+func contains(moves []lockitdown.GameMove, move lockitdown.GameMove) bool {
+	key := moveKey(move)
+	for _, m := range moves {
+		if moveKey(m) == key {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
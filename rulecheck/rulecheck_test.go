@@ -0,0 +1,157 @@
+package rulecheck
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rwsargent/boardbots-go/bot"
+	"github.com/rwsargent/boardbots-go/client"
+	"github.com/rwsargent/boardbots-go/client/fakeserver"
+	"github.com/rwsargent/boardbots-go/lockitdown"
+	"github.com/stretchr/testify/assert"
+)
+
+type testGame struct {
+	server  *fakeserver.Server
+	players []*client.BoardBotClient[lockitdown.TransportState]
+	id      string
+}
+
+func newTestClient(t *testing.T, server *fakeserver.Server, username string) *client.BoardBotClient[lockitdown.TransportState] {
+	bbClient, err := client.NewBoardBotClient[lockitdown.TransportState](client.Credentials{Username: username}, server.URL)
+	assert.Nil(t, err)
+	assert.Nil(t, bbClient.Authenticate(context.Background()))
+	return bbClient
+}
+
+func startTestGame(t *testing.T) *testGame {
+	server := fakeserver.New(fakeserver.DefaultGameDef)
+	gameId, err := server.StartGame("host", "oppo")
+	assert.Nil(t, err)
+	host := newTestClient(t, server, "host")
+	oppo := newTestClient(t, server, "oppo")
+	return &testGame{server, []*client.BoardBotClient[lockitdown.TransportState]{host, oppo}, gameId.String()}
+}
+
+// play makes moves picked from the server's potential moves.
+func (g *testGame) play(t *testing.T, moves int) {
+	ctx := context.Background()
+	for i := 0; i < moves; i++ {
+		game, err := g.players[0].Game(ctx, g.id)
+		assert.Nil(t, err)
+		serverMoves, err := g.players[0].GetPossibleMoves(ctx, g.id)
+		assert.Nil(t, err)
+		player := game.State.PlayerTurn
+		_, err = g.players[player-1].MakeMove(ctx, g.id, client.MoveCommand{Json: serverMoves[i%len(serverMoves)]})
+		assert.Nil(t, err)
+	}
+}
+
+// tamper turns a robot on the server, without making a move.
+func (g *testGame) tamper(t *testing.T) {
+	id := uuid.MustParse(g.id)
+	state := g.server.GameState(id)
+	assert.NotEmpty(t, state.Robots)
+	state.Robots[0].Direction = lockitdown.Pair{Q: -state.Robots[0].Direction.Q, R: -state.Robots[0].Direction.R}
+	assert.Nil(t, g.server.SetGameState(id, state))
+}
+
+func TestCheck(t *testing.T) {
+	game := startTestGame(t)
+	defer game.server.Close()
+	game.play(t, 8)
+
+	divergences, err := Check(context.Background(), game.players[0], game.id)
+	assert.Nil(t, err)
+	assert.Empty(t, divergences)
+
+	game.tamper(t)
+	divergences, err = Check(context.Background(), game.players[0], game.id)
+	assert.Nil(t, err)
+	assert.Len(t, divergences, 1)
+	assert.Equal(t, StateMismatch, divergences[0].Kind)
+	assert.Equal(t, 8, divergences[0].Move)
+	assert.Contains(t, divergences[0].Detail, "robot at")
+	assert.Contains(t, divergences[0].Position, "engine:\n")
+}
+
+func TestWatch(t *testing.T) {
+	game := startTestGame(t)
+	defer game.server.Close()
+
+	var lock sync.Mutex
+	var divergences []Divergence
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- Watch(ctx, game.players[0], game.id, func(divergence Divergence) {
+			lock.Lock()
+			defer lock.Unlock()
+			divergences = append(divergences, divergence)
+		})
+	}()
+
+	seen := func(moves int) func() bool {
+		return func() bool {
+			history, err := game.players[0].MoveHistory(context.Background(), game.id)
+			return err == nil && len(history) == moves
+		}
+	}
+	game.play(t, 6)
+	assert.Eventually(t, seen(6), time.Second, 5*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	lock.Lock()
+	assert.Empty(t, divergences)
+	lock.Unlock()
+
+	// The engine replays the next move from the state before tampering.
+	game.tamper(t)
+	game.play(t, 1)
+	assert.Eventually(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(divergences) > 0
+	}, time.Second, 5*time.Millisecond)
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestReplayRejectedMove(t *testing.T) {
+	c := &checker{local: lockitdown.NewGame(fakeserver.DefaultGameDef)}
+	placed := lockitdown.GameMove{Player: 0, Mover: &lockitdown.PlaceRobot{Robot: lockitdown.Pair{Q: 0, R: -5}, Direction: lockitdown.SE}}
+	history := []client.MoveResp{
+		{Index: 0, Move: bot.LockItDownMove(placed)},
+		{Index: 1, Move: client.MoveT{Player: 2, Action: "Advance"}},
+	}
+
+	divergences, replayed := c.replay(history)
+	assert.False(t, replayed)
+	assert.Equal(t, 1, c.numMoves)
+	assert.Len(t, divergences, 2)
+	assert.Equal(t, ServerOnlyMove, divergences[0].Kind)
+	assert.Equal(t, RejectedMove, divergences[1].Kind)
+	assert.Equal(t, 1, divergences[1].Move)
+	assert.Contains(t, divergences[1].Position, "1SE")
+}
+
+func TestCompareMoves(t *testing.T) {
+	state := lockitdown.NewGame(fakeserver.DefaultGameDef)
+	moves := state.PossibleMoves(nil)
+	serverMoves := make([]client.MoveT, 0, len(moves))
+	for _, move := range moves[1:] {
+		serverMoves = append(serverMoves, bot.LockItDownMove(move))
+	}
+	assert.Empty(t, compareMoves(state, 0, append(serverMoves, bot.LockItDownMove(moves[0]))))
+
+	advance := lockitdown.GameMove{Player: 0, Mover: &lockitdown.AdvanceRobot{Robot: lockitdown.Pair{}}}
+	divergences := compareMoves(state, 0, append(serverMoves, bot.LockItDownMove(advance)))
+	assert.Len(t, divergences, 2)
+	assert.Equal(t, ServerOnlyMove, divergences[0].Kind)
+	assert.Equal(t, "player 1 Move {0, 0}", divergences[0].Detail)
+	assert.Equal(t, EngineOnlyMove, divergences[1].Kind)
+	assert.Equal(t, moveKey(moves[0]), divergences[1].Detail)
+}